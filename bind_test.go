@@ -0,0 +1,78 @@
+package water
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBindSources(t *testing.T) {
+	type req struct {
+		ID    int    `uri:"id" form:"id" json:"id"`
+		Page  int    `form:"page"`
+		Token string `header:"X-Token" form:"token"`
+		Name  string `json:"name" form:"name" binding:"required"`
+	}
+
+	serveBind := func(h func(ctx *Context), url, body string) *httptest.ResponseRecorder {
+		return serve(newTestEngine("POST", "/<id>", h), "POST", url, body,
+			HeaderContentType, MIMEApplicationJSON, "X-Token", "t1")
+	}
+
+	Convey("BindURI, BindHeader and BindQuery", t, func() {
+		serveBind(func(ctx *Context) {
+			var u struct {
+				ID int `uri:"id"`
+			}
+			So(ctx.BindURI(&u), ShouldBeNil)
+			So(u.ID, ShouldEqual, 7)
+
+			var h struct {
+				Token string `header:"X-Token"`
+			}
+			So(ctx.BindHeader(&h), ShouldBeNil)
+			So(h.Token, ShouldEqual, "t1")
+
+			var q struct {
+				Page int `form:"page"`
+			}
+			So(ctx.BindQuery(&q), ShouldBeNil)
+			So(q.Page, ShouldEqual, 2)
+
+			var bad struct {
+				ID int `uri:"id" binding:"max=5"`
+			}
+			So(ctx.BindURI(&bad), ShouldNotBeNil)
+		}, "/7?page=2", "")
+	})
+
+	Convey("BindAll", t, func() {
+		serveBind(func(ctx *Context) {
+			var r req
+			So(ctx.BindAll(&r), ShouldBeNil)
+			So(r, ShouldResemble, req{ID: 7, Page: 2, Token: "t1", Name: "a"})
+		}, "/7?page=2&id=1&token=t0", `{"id":3,"name":"a"}`)
+
+		// validated once at the end
+		resp := serveBind(func(ctx *Context) {
+			var r req
+			err := ctx.BindAll(&r)
+			So(err, ShouldNotBeNil)
+			So(r.ID, ShouldEqual, 7)
+			ctx.String(400, err.Error())
+		}, "/7", `{}`)
+		So(resp.Code, ShouldEqual, http.StatusBadRequest)
+	})
+
+	Convey("unsupported media type", t, func() {
+		e := newTestEngine("POST", "/", func(ctx *Context) {
+			var v struct{}
+			So(ctx.Bind(&v), ShouldNotBeNil)
+		})
+
+		resp := serve(e, "POST", "/", "x", HeaderContentType, "image/png")
+		So(resp.Code, ShouldEqual, http.StatusUnsupportedMediaType)
+	})
+}
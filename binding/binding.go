@@ -1,6 +1,9 @@
 package binding
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 )
 
@@ -11,6 +14,12 @@ const (
 	MIMEPlain             = "text/plain"
 	MIMEPOSTForm          = "application/x-www-form-urlencoded"
 	MIMEMultipartPOSTForm = "multipart/form-data"
+	MIMEYAML              = "application/yaml"
+	MIMEYAML2             = "application/x-yaml"
+	MIMETOML              = "application/toml"
+	MIMEMSGPACK           = "application/msgpack"
+	MIMEMSGPACK2          = "application/x-msgpack"
+	MIMECBOR              = "application/cbor"
 )
 
 type Bindinger interface {
@@ -18,6 +27,12 @@ type Bindinger interface {
 	Bind(*http.Request, interface{}) error
 }
 
+// Decoder is implemented by the Bindingers which can fill obj without
+// validating it, so that several sources can be merged before one validation.
+type Decoder interface {
+	Decode(*http.Request, interface{}) error
+}
+
 var (
 	JSON          = jsonBinding{}
 	XML           = xmlBinding{}
@@ -27,30 +42,142 @@ var (
 	Query         = queryBinding{}
 	Header        = headerBinding{}
 	Uri           = uriBinding{}
+	YAML          = yamlBinding{}
 )
 
 // NewBindinger returns the appropriate Binding instance based on HTTP method
-// and content type.
+// and content type, see Lookup.
+// The Bindinger of unknown content type returns *UnsupportedMediaTypeError.
 func NewBindinger(method, contentType string) Bindinger {
-	if method == http.MethodGet {
+	if method == http.MethodGet || contentType == "" {
 		return Form
 	}
 
-	if (method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch) &&
-		contentType == MIMEMultipartPOSTForm {
-		return FormMultipart
+	if contentType == MIMEMultipartPOSTForm &&
+		method != http.MethodPost && method != http.MethodPut && method != http.MethodPatch {
+		return Form
 	}
 
-	switch contentType {
-	case MIMEJSON:
-		return JSON
-	case MIMEXML, MIMEXML2:
-		return XML
-	case MIMEPOSTForm:
-		return Form
-	default:
-		return Form
+	if b := Lookup(contentType); b != nil {
+		return b
+	}
+
+	return unsupportedBinding{contentType}
+}
+
+// UnsupportedMediaTypeError is returned when no Bindinger is registered for
+// the content type of request.
+type UnsupportedMediaTypeError struct {
+	MediaType string
+}
+
+func (e *UnsupportedMediaTypeError) Error() string {
+	return fmt.Sprintf("unsupported media type %q", e.MediaType)
+}
+
+// StatusCode returns 415.
+func (e *UnsupportedMediaTypeError) StatusCode() int {
+	return http.StatusUnsupportedMediaType
+}
+
+type unsupportedBinding struct {
+	mediaType string
+}
+
+func (unsupportedBinding) Name() string {
+	return "unsupported"
+}
+
+func (b unsupportedBinding) Bind(req *http.Request, obj interface{}) error {
+	return b.Decode(req, obj)
+}
+
+func (b unsupportedBinding) Decode(*http.Request, interface{}) error {
+	return &UnsupportedMediaTypeError{MediaType: b.mediaType}
+}
+
+// BodyTooLargeError is returned when the request body exceeds the limit
+// set by http.MaxBytesReader.
+type BodyTooLargeError struct {
+	Limit int64
+}
+
+func (e *BodyTooLargeError) Error() string {
+	return fmt.Sprintf("request body too large, limit is %d bytes", e.Limit)
+}
+
+// StatusCode returns 413.
+func (e *BodyTooLargeError) StatusCode() int {
+	return http.StatusRequestEntityTooLarge
+}
+
+// BodyError converts the error of reading request body to *BodyTooLargeError
+// if the body exceeds the limit, otherwise returns err.
+func BodyError(err error) error {
+	var mbe *http.MaxBytesError
+	if errors.As(err, &mbe) {
+		return &BodyTooLargeError{Limit: mbe.Limit}
+	}
+
+	return err
+}
+
+// The errors of decoding body with the limits of JSONConfig and XMLConfig.
+var (
+	ErrUnknownField    = errors.New("unknown field")
+	ErrTooDeep         = errors.New("nested too deep")
+	ErrTrailingData    = errors.New("trailing data after the top-level value")
+	ErrTooManyEntities = errors.New("too many entity references")
+)
+
+// DecodeError is an error of decoding body at Path, which is the field path
+// of json, e.g. items[0].name, or the element path of xml, e.g. order.items.item.
+type DecodeError struct {
+	Path string
+	Err  error
+}
+
+func (e *DecodeError) Error() string {
+	if e.Path == "" {
+		return e.Err.Error()
+	}
+
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// bodyReader remembers the read error of request body, since decoders
+// like jsoniter flatten it into their own error.
+type bodyReader struct {
+	io.Reader
+	err error
+}
+
+func (r *bodyReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if err != nil && err != io.EOF {
+		r.err = err
 	}
+	return n, err
+}
+
+// check prefers the *BodyTooLargeError of reading to the decode err.
+func (r *bodyReader) check(err error) error {
+	if err != nil && r.err != nil {
+		if e := BodyError(r.err); e != r.err {
+			return e
+		}
+	}
+
+	return BodyError(err)
+}
+
+// Validate validates obj by Validator, it is a no-op when Validator is nil.
+func Validate(obj interface{}) error {
+	return validate(obj)
 }
 
 func validate(obj interface{}) error {
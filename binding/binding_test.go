@@ -158,6 +158,13 @@ func TestBindingNewBindinger(t *testing.T) {
 
 	assert.Equal(t, FormMultipart, NewBindinger("POST", MIMEMultipartPOSTForm))
 	assert.Equal(t, FormMultipart, NewBindinger("PUT", MIMEMultipartPOSTForm))
+	assert.Equal(t, Form, NewBindinger("DELETE", MIMEMultipartPOSTForm))
+
+	assert.Equal(t, Form, NewBindinger("POST", ""))
+	assert.Equal(t, JSON, NewBindinger("POST", "application/problem+json"))
+	assert.Equal(t, XML, NewBindinger("POST", "application/atom+xml"))
+	assert.Equal(t, YAML, NewBindinger("POST", MIMEYAML2))
+	assert.Equal(t, unsupportedBinding{"text/plain"}, NewBindinger("POST", MIMEPlain))
 }
 
 func TestBindingJSONNilBody(t *testing.T) {
@@ -1304,3 +1311,32 @@ func requestWithBody(method, path, body string) (req *http.Request) {
 	req, _ = http.NewRequest(method, path, bytes.NewBufferString(body))
 	return
 }
+
+func TestBindingBodyTooLarge(t *testing.T) {
+	newReq := func(contentType, body string) *http.Request {
+		req, _ := http.NewRequest("POST", "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		req.Body = http.MaxBytesReader(nil, req.Body, 8)
+		return req
+	}
+
+	for _, tt := range []struct {
+		b    Bindinger
+		ct   string
+		body string
+	}{
+		{JSON, MIMEJSON, `{"foo": "0123456789"}`},
+		{XML, MIMEXML, `<FooStruct><foo>0123456789</foo></FooStruct>`},
+		{Form, MIMEPOSTForm, "foo=0123456789"},
+	} {
+		obj := FooStruct{}
+		err := tt.b.Bind(newReq(tt.ct, tt.body), &obj)
+
+		var e *BodyTooLargeError
+		assert.True(t, errors.As(err, &e), "%s: %v", tt.b.Name(), err)
+		assert.Equal(t, int64(8), e.Limit)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, e.StatusCode())
+	}
+
+	assert.Equal(t, io.EOF, BodyError(io.EOF))
+}
@@ -0,0 +1,47 @@
+//go:build !nocbor
+// +build !nocbor
+
+package binding
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ugorji/go/codec"
+)
+
+// go build -tags nocbor, to remove the CBOR binding
+var (
+	CBOR = cborBinding{}
+
+	cborHandle codec.CborHandle
+)
+
+func init() {
+	Register(MIMECBOR, CBOR)
+}
+
+type cborBinding struct{}
+
+func (cborBinding) Name() string {
+	return "cbor"
+}
+
+func (b cborBinding) Bind(req *http.Request, obj interface{}) error {
+	if err := b.Decode(req, obj); err != nil {
+		return err
+	}
+
+	return validate(obj)
+}
+
+func (b cborBinding) Decode(req *http.Request, obj interface{}) error {
+	if req == nil || req.Body == nil {
+		return fmt.Errorf("invalid request for bind %s", b.Name())
+	}
+	defer req.Body.Close()
+
+	body := &bodyReader{Reader: req.Body}
+	decoder := codec.NewDecoder(body, &cborHandle)
+	return body.check(decoder.Decode(obj))
+}
@@ -0,0 +1,25 @@
+//go:build !nocbor
+// +build !nocbor
+
+package binding
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ugorji/go/codec"
+)
+
+func TestBindingCBOR(t *testing.T) {
+	test := FooStruct{Foo: "bar"}
+
+	var buf bytes.Buffer
+	assert.NoError(t, codec.NewEncoder(&buf, &cborHandle).Encode(test))
+
+	assert.Equal(t, CBOR, NewBindinger("POST", "application/vnd.foo+cbor"))
+	testBodyBinding(t,
+		CBOR, "cbor",
+		"/", "/",
+		buf.String(), string(buf.Bytes()[1:]))
+}
@@ -0,0 +1,115 @@
+package binding
+
+import (
+	ojson "encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONConfig(t *testing.T) {
+	type item struct {
+		Name string `json:"name"`
+	}
+	type order struct {
+		appkey
+		ID    int                    `json:"id"`
+		Items []item                 `json:"items"`
+		Meta  map[string]interface{} `json:"meta"`
+		Extra interface{}            `json:"extra"`
+		Raw   ojson.RawMessage       `json:"raw"`
+	}
+
+	decode := func(c JSONConfig, body string) (order, error) {
+		var obj order
+		err := c.Decode(requestWithBody("POST", "/", body), &obj)
+		return obj, err
+	}
+
+	// the default ignores all
+	_, err := decode(JSONConfig{}, `{"id":1,"items":[{"name":"a","x":1}]} {}`)
+	assert.NoError(t, err)
+
+	strict := JSONConfig{DisallowUnknownFields: true, RejectTrailingData: true, MaxDepth: 3}
+
+	obj, err := decode(strict, `{"ID":1,"appkey":"k","items":[{"name":"a"}],"meta":{"a":{"b":1}},"extra":{"x":[1]},"raw":{"y":{}}}`)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, obj.ID)
+	assert.Equal(t, "k", obj.Appkey)
+
+	_, err = decode(strict, `{"items":[{"name":"a"},{"name":"b","x1":1}]}`)
+	var de *DecodeError
+	assert.True(t, errors.As(err, &de))
+	assert.Equal(t, "items[1].x1", de.Path)
+	assert.ErrorIs(t, err, ErrUnknownField)
+	assert.EqualError(t, err, "items[1].x1: unknown field")
+
+	_, err = decode(strict, `{"meta":{"a":{"b":{"c":1}}}}`)
+	assert.ErrorIs(t, err, ErrTooDeep)
+	assert.Equal(t, "meta.a.b", err.(*DecodeError).Path)
+
+	deep := `{"meta":` + strings.Repeat("[", maxJSONCheckDepth) + strings.Repeat("]", maxJSONCheckDepth) + `}`
+	_, err = decode(JSONConfig{DisallowUnknownFields: true}, deep)
+	assert.ErrorIs(t, err, ErrTooDeep)
+	assert.True(t, strings.HasPrefix(err.(*DecodeError).Path, "meta[0][0]"))
+
+	_, err = decode(strict, `{"id":1} {"id":2}`)
+	assert.ErrorIs(t, err, ErrTrailingData)
+
+	// the syntax error is reported by the decoder
+	_, err = decode(strict, `{"id":1`)
+	assert.Error(t, err)
+	assert.False(t, errors.As(err, &de))
+
+	var v struct {
+		N interface{} `json:"n"`
+	}
+	assert.NoError(t, JSONConfig{UseNumber: true}.Bind(requestWithBody("POST", "/", `{"n":1}`), &v))
+	assert.Equal(t, ojson.Number("1"), v.N)
+}
+
+func TestJSONConfigGlobal(t *testing.T) {
+	defer func(c JSONConfig) { DefaultJSONConfig = c }(DefaultJSONConfig)
+	DefaultJSONConfig = JSONConfig{DisallowUnknownFields: true}
+
+	var obj FooStruct
+	err := JSON.Bind(requestWithBody("POST", "/", `{"foo":"bar","bar":1}`), &obj)
+	assert.ErrorIs(t, err, ErrUnknownField)
+
+	req := requestWithBody("POST", "/", `{"foo":"`+strings.Repeat("a", 16)+`"}`)
+	req.Body = http.MaxBytesReader(nil, req.Body, 8)
+	err = JSON.Bind(req, &obj)
+	assert.Equal(t, &BodyTooLargeError{Limit: 8}, err)
+}
+
+func TestXMLConfig(t *testing.T) {
+	type order struct {
+		Items []string `xml:"items>item"`
+		Note  string   `xml:"note,attr"`
+	}
+
+	decode := func(c XMLConfig, body string) error {
+		var obj order
+		return c.Decode(requestWithBody("POST", "/", body), &obj)
+	}
+
+	body := `<order note="a&amp;b"><items><item>x &lt; y</item><item><![CDATA[&&&]]></item></items></order>`
+	assert.NoError(t, decode(XMLConfig{}, body))
+	assert.NoError(t, decode(XMLConfig{MaxDepth: 3, MaxEntities: 2}, body))
+
+	err := decode(XMLConfig{MaxDepth: 2}, body)
+	assert.ErrorIs(t, err, ErrTooDeep)
+	assert.EqualError(t, err, "order.items.item: nested too deep")
+
+	err = decode(XMLConfig{MaxEntities: 1}, body)
+	assert.ErrorIs(t, err, ErrTooManyEntities)
+	assert.Equal(t, "order.items.item", err.(*DecodeError).Path)
+
+	defer func(c XMLConfig) { DefaultXMLConfig = c }(DefaultXMLConfig)
+	DefaultXMLConfig = XMLConfig{MaxDepth: 1}
+	var obj order
+	assert.ErrorIs(t, XML.Bind(requestWithBody("POST", "/", body), &obj), ErrTooDeep)
+}
@@ -16,6 +16,7 @@ func TestSliceValidateError(t *testing.T) {
 		want string
 	}{
 		{"has nil elements", sliceValidateError{errors.New("test error"), nil}, "[0]: test error"},
+		{"keeps index", sliceValidateError{nil, errors.New("test error")}, "[1]: test error"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,270 @@
+package binding
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError is a field failed the validation or parsing.
+type FieldError struct {
+	// Source is where the value is from, e.g. query or cookie, empty for the
+	// bound struct.
+	Source string `json:"source,omitempty" xml:"source,omitempty"`
+	// Field is the path by the names of json or form tag, e.g. items[0].name.
+	Field   string      `json:"field" xml:"field"`
+	Rule    string      `json:"rule" xml:"rule"`
+	Param   string      `json:"param,omitempty" xml:"param,omitempty"`
+	Value   interface{} `json:"value,omitempty" xml:"-"`
+	Message string      `json:"message" xml:"message"`
+
+	kind reflect.Kind
+}
+
+func (e *FieldError) Error() string {
+	return e.Message
+}
+
+// FieldErrors are all fields failed the validation.
+type FieldErrors []*FieldError
+
+func (es FieldErrors) Error() string {
+	ls := make([]string, len(es))
+	for i, e := range es {
+		ls[i] = e.Error()
+	}
+
+	return strings.Join(ls, "; ")
+}
+
+// StatusCode returns 422.
+func (es FieldErrors) StatusCode() int {
+	return http.StatusUnprocessableEntity
+}
+
+// Translator makes the message of FieldError.
+type Translator interface {
+	Translate(*FieldError) string
+}
+
+// Messages is a Translator by the message templates of rules, the template can
+// use {field}, {param} and {value}.
+// The template is looked up by "rule.string", "rule.number" or "rule.items"
+// by the kind of value, then "rule", then "*".
+type Messages map[string]string
+
+func (m Messages) Translate(e *FieldError) string {
+	var class string
+	switch e.kind {
+	case reflect.String:
+		class = "string"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		class = "items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		class = "number"
+	}
+
+	tpl, ok := m[e.Rule+"."+class]
+	if !ok {
+		if tpl, ok = m[e.Rule]; !ok {
+			tpl = m["*"]
+		}
+	}
+
+	return strings.NewReplacer(
+		"{field}", e.Field,
+		"{param}", e.Param,
+		"{value}", fmt.Sprint(e.Value),
+	).Replace(tpl)
+}
+
+// The built-in Translators.
+var (
+	English = Messages{
+		"*":          "{field} is invalid",
+		"required":   "{field} is required",
+		"min.string": "{field} must be at least {param} characters long",
+		"min.items":  "{field} must contain at least {param} items",
+		"min":        "{field} must be {param} or greater",
+		"max.string": "{field} must be at most {param} characters long",
+		"max.items":  "{field} must contain at most {param} items",
+		"max":        "{field} must be {param} or less",
+		"len.string": "{field} must be {param} characters long",
+		"len.items":  "{field} must contain {param} items",
+		"len":        "{field} must be equal to {param}",
+		"eq":         "{field} must be equal to {param}",
+		"ne":         "{field} must not be equal to {param}",
+		"gt":         "{field} must be greater than {param}",
+		"gte":        "{field} must be greater than or equal to {param}",
+		"lt":         "{field} must be less than {param}",
+		"lte":        "{field} must be less than or equal to {param}",
+		"oneof":      "{field} must be one of [{param}]",
+		"email":      "{field} must be a valid email address",
+		"url":        "{field} must be a valid URL",
+		"uuid":       "{field} must be a valid UUID",
+		"alpha":      "{field} can only contain alphabetic characters",
+		"alphanum":   "{field} can only contain alphanumeric characters",
+		"numeric":    "{field} must be a valid numeric value",
+		"ip":         "{field} must be a valid IP address",
+		"datetime":   "{field} does not match the format {param}",
+	}
+
+	Chinese = Messages{
+		"*":          "{field}格式不正确",
+		"required":   "{field}为必填字段",
+		"min.string": "{field}长度必须至少为{param}个字符",
+		"min.items":  "{field}必须至少包含{param}项",
+		"min":        "{field}最小只能为{param}",
+		"max.string": "{field}长度不能超过{param}个字符",
+		"max.items":  "{field}最多只能包含{param}项",
+		"max":        "{field}必须小于或等于{param}",
+		"len.string": "{field}长度必须是{param}个字符",
+		"len.items":  "{field}必须包含{param}项",
+		"len":        "{field}必须等于{param}",
+		"eq":         "{field}必须等于{param}",
+		"ne":         "{field}不能等于{param}",
+		"gt":         "{field}必须大于{param}",
+		"gte":        "{field}必须大于或等于{param}",
+		"lt":         "{field}必须小于{param}",
+		"lte":        "{field}必须小于或等于{param}",
+		"oneof":      "{field}必须是[{param}]中的一个",
+		"email":      "{field}必须是一个有效的邮箱",
+		"url":        "{field}必须是一个有效的URL",
+		"uuid":       "{field}必须是一个有效的UUID",
+		"alpha":      "{field}只能包含字母",
+		"alphanum":   "{field}只能包含字母和数字",
+		"numeric":    "{field}必须是一个有效的数值",
+		"ip":         "{field}必须是一个有效的IP地址",
+		"datetime":   "{field}的格式必须是{param}",
+	}
+
+	// DefaultTranslator is used when no Translator is given.
+	DefaultTranslator Translator = English
+)
+
+// NewFieldErrors converts the validation error of obj to FieldErrors translated
+// by tr, nil if err is not a validation error.
+// obj is used to name the fields by the json or form tag.
+func NewFieldErrors(obj interface{}, err error, tr Translator) FieldErrors {
+	if tr == nil {
+		tr = DefaultTranslator
+	}
+
+	var es FieldErrors
+	appendFieldErrors(&es, reflect.TypeOf(obj), "", err, tr)
+
+	return es
+}
+
+func appendFieldErrors(es *FieldErrors, typ reflect.Type, prefix string, err error, tr Translator) {
+	var ses sliceValidateError
+	if errors.As(err, &ses) {
+		var elem reflect.Type
+		if typ = indirectType(typ); typ != nil && (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) {
+			elem = typ.Elem()
+		}
+
+		for i, e := range ses {
+			if e != nil {
+				appendFieldErrors(es, elem, fmt.Sprintf("%s[%d]", prefix, i), e, tr)
+			}
+		}
+		return
+	}
+
+	var ves validator.ValidationErrors
+	if !errors.As(err, &ves) {
+		return
+	}
+
+	for _, ve := range ves {
+		field := fieldPath(typ, ve.StructNamespace())
+		if prefix != "" && field != "" && field[0] != '[' {
+			field = "." + field
+		}
+
+		e := &FieldError{
+			Field: prefix + field,
+			Rule:  ve.Tag(),
+			Param: ve.Param(),
+			Value: ve.Value(),
+			kind:  ve.Kind(),
+		}
+		e.Message = tr.Translate(e)
+
+		*es = append(*es, e)
+	}
+}
+
+// fieldPath converts the namespace of validator, e.g. Order.Items[0].Name,
+// to the path by tag names, e.g. items[0].name.
+func fieldPath(typ reflect.Type, namespace string) string {
+	parts := strings.Split(namespace, ".")[1:] // the first is the name of struct
+
+	var b strings.Builder
+	for _, part := range parts {
+		name, index := part, ""
+		if i := strings.IndexByte(part, '['); i >= 0 {
+			name, index = part[:i], part[i:]
+		}
+
+		tagName := name
+		if typ = indirectType(typ); typ != nil && typ.Kind() == reflect.Struct {
+			if f, ok := typ.FieldByName(name); ok {
+				tagName, typ = fieldTagName(f), f.Type
+			} else {
+				typ = nil
+			}
+		} else {
+			typ = nil
+		}
+
+		for n := strings.Count(index, "["); n > 0 && typ != nil; n-- {
+			if typ = indirectType(typ); typ != nil {
+				switch typ.Kind() {
+				case reflect.Slice, reflect.Array, reflect.Map:
+					typ = typ.Elem()
+				default:
+					typ = nil
+				}
+			}
+		}
+
+		if tagName != "" && b.Len() > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(tagName + index)
+	}
+
+	return b.String()
+}
+
+// fieldTagName returns the name of json tag, form tag or field,
+// "" for the embedded struct without tag.
+func fieldTagName(f reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		if name, _ := head(f.Tag.Get(tag), ","); name != "" && name != "-" {
+			return name
+		}
+	}
+
+	if f.Anonymous {
+		return ""
+	}
+
+	return f.Name
+}
+
+func indirectType(typ reflect.Type) reflect.Type {
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	return typ
+}
@@ -0,0 +1,65 @@
+package binding
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fieldErrorItem struct {
+	Name string `json:"name" binding:"required"`
+	Qty  int    `form:"qty" binding:"min=1"`
+}
+
+type fieldErrorOrder struct {
+	appkey `binding:"-"`
+	FooStruct
+	Code  string            `json:"code" binding:"len=3"`
+	Items []*fieldErrorItem `json:"items" binding:"min=1,dive"`
+	Tags  map[string]string `json:"-" binding:"dive,max=2"`
+}
+
+func TestNewFieldErrors(t *testing.T) {
+	obj := &fieldErrorOrder{
+		Code:  "ab",
+		Items: []*fieldErrorItem{{Name: "a", Qty: 1}, {Qty: 0}},
+		Tags:  map[string]string{"k": "long"},
+	}
+
+	es := NewFieldErrors(obj, Validate(obj), nil)
+	assert.Len(t, es, 5)
+
+	got := make(map[string]*FieldError)
+	for _, e := range es {
+		got[e.Field] = e
+	}
+	assert.Equal(t, "foo is required", got["foo"].Message)
+	assert.Equal(t, "code must be 3 characters long", got["code"].Message)
+	assert.Equal(t, "ab", got["code"].Value)
+	assert.Equal(t, "required", got["items[1].name"].Rule)
+	assert.Equal(t, "1", got["items[1].qty"].Param)
+	assert.Equal(t, "items[1].qty must be 1 or greater", got["items[1].qty"].Message)
+	assert.Equal(t, "Tags[k] must be at most 2 characters long", got["Tags[k]"].Message)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, es.StatusCode())
+	assert.Nil(t, NewFieldErrors(obj, errors.New("x"), nil))
+}
+
+func TestNewFieldErrorsSlice(t *testing.T) {
+	obj := []fieldErrorItem{{Name: "a", Qty: 1}, {Name: "b"}, {Qty: 2}}
+
+	es := NewFieldErrors(&obj, Validate(&obj), Chinese)
+	assert.Len(t, es, 2)
+	assert.Equal(t, "[1].qty", es[0].Field)
+	assert.Equal(t, "[1].qty最小只能为1", es[0].Message)
+	assert.Equal(t, "[2].name", es[1].Field)
+	assert.Equal(t, "[2].name为必填字段", es[1].Message)
+}
+
+func TestMessages(t *testing.T) {
+	m := Messages{"*": "{field} {value}", "max.items": "{field} max {param}"}
+	assert.Equal(t, "a 1", m.Translate(&FieldError{Field: "a", Rule: "x", Value: 1}))
+	assert.Equal(t, "a 1", m.Translate(&FieldError{Field: "a", Rule: "max", Value: 1}))
+}
@@ -34,18 +34,22 @@ func (formBinding) Bind2(req *http.Request, obj interface{}) error {
 	return validate(obj)
 }
 
-func (formBinding) Bind(req *http.Request, obj interface{}) error {
+func (b formBinding) Bind(req *http.Request, obj interface{}) error {
+	if err := b.Decode(req, obj); err != nil {
+		return err
+	}
+
+	return validate(obj)
+}
+
+func (formBinding) Decode(req *http.Request, obj interface{}) error {
 	if req.Form == nil {
 		if err := req.ParseForm(); err != nil {
-			return err
+			return BodyError(err)
 		}
 	}
 
-	if err := MapForm(obj, req.Form, nil, "form"); err != nil {
-		return err
-	}
-
-	return validate(obj)
+	return MapForm(obj, req.Form, nil, "form")
 }
 
 type formMultipartBinding struct{}
@@ -69,17 +73,21 @@ func (b formMultipartBinding) Bind2(req *http.Request, obj interface{}) error {
 }
 
 func (b formMultipartBinding) Bind(req *http.Request, obj interface{}) error {
+	if err := b.Decode(req, obj); err != nil {
+		return err
+	}
+
+	return validate(obj)
+}
+
+func (formMultipartBinding) Decode(req *http.Request, obj interface{}) error {
 	if req.MultipartForm == nil {
 		if err := req.ParseMultipartForm(defaultMultipartMemory); err != nil {
-			return err
+			return BodyError(err)
 		}
 	}
 
-	if err := MapForm(obj, req.MultipartForm.Value, req.MultipartForm.File, "form"); err != nil {
-		return err
-	}
-
-	return validate(obj)
+	return MapForm(obj, req.MultipartForm.Value, req.MultipartForm.File, "form")
 }
 
 type formSource map[string][]string
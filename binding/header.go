@@ -20,14 +20,18 @@ func (headerBinding) Bind2(req *http.Request, obj interface{}) error {
 	return validate(obj)
 }
 
-func (headerBinding) Bind(req *http.Request, obj interface{}) error {
-	if err := MapForm(obj, req.Header, nil, "header"); err != nil {
+func (b headerBinding) Bind(req *http.Request, obj interface{}) error {
+	if err := b.Decode(req, obj); err != nil {
 		return err
 	}
 
 	return validate(obj)
 }
 
+func (headerBinding) Decode(req *http.Request, obj interface{}) error {
+	return MapForm(obj, req.Header, nil, "header")
+}
+
 type headerSource map[string][]string
 
 var _ setter = headerSource(nil)
@@ -1,8 +1,15 @@
 package binding
 
 import (
+	"bytes"
+	ojson "encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 
 	jsoniter "github.com/json-iterator/go"
 )
@@ -11,6 +18,27 @@ var (
 	json = jsoniter.ConfigCompatibleWithStandardLibrary
 )
 
+// JSONConfig is the config of decoding JSON body, the zero value is the
+// default behavior of JSON binding.
+// It is also a Bindinger for a call, e.g.
+//
+//	ctx.BindWith(&obj, binding.JSONConfig{DisallowUnknownFields: true})
+type JSONConfig struct {
+	// DisallowUnknownFields rejects the object key which is not a field of
+	// the target struct.
+	DisallowUnknownFields bool
+	// UseNumber decodes the number into interface{} as json.Number instead of float64.
+	UseNumber bool
+	// MaxDepth is the max nesting of objects and arrays, 0 means the limit of
+	// the decoder, 10000.
+	MaxDepth int
+	// RejectTrailingData rejects the data after the top-level value.
+	RejectTrailingData bool
+}
+
+// DefaultJSONConfig is used by JSON binding.
+var DefaultJSONConfig JSONConfig
+
 type jsonBinding struct{}
 
 func (jsonBinding) Name() string {
@@ -18,15 +46,368 @@ func (jsonBinding) Name() string {
 }
 
 func (b jsonBinding) Bind(req *http.Request, obj interface{}) error {
+	if err := b.Decode(req, obj); err != nil {
+		return err
+	}
+
+	return validate(obj)
+}
+
+func (b jsonBinding) Decode(req *http.Request, obj interface{}) error {
+	return DefaultJSONConfig.Decode(req, obj)
+}
+
+func (JSONConfig) Name() string {
+	return "json"
+}
+
+func (c JSONConfig) Bind(req *http.Request, obj interface{}) error {
+	if err := c.Decode(req, obj); err != nil {
+		return err
+	}
+
+	return validate(obj)
+}
+
+// Decode returns *DecodeError if the body exceeds the limits of c.
+func (c JSONConfig) Decode(req *http.Request, obj interface{}) error {
 	if req == nil || req.Body == nil {
-		return fmt.Errorf("invalid request for bind %s", b.Name())
+		return fmt.Errorf("invalid request for bind %s", c.Name())
 	}
 	defer req.Body.Close()
 
-	decoder := json.NewDecoder(req.Body)
-	if err := decoder.Decode(obj); err != nil {
+	body := &bodyReader{Reader: req.Body}
+	if !c.DisallowUnknownFields && c.MaxDepth <= 0 && !c.RejectTrailingData {
+		return body.check(c.newDecoder(body).Decode(obj))
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return BodyError(err)
+	}
+
+	jc := &jsonChecker{config: c, data: data}
+	if err := jc.check(reflect.TypeOf(obj)); err != nil {
 		return err
 	}
 
-	return validate(obj)
+	return c.newDecoder(bytes.NewReader(data)).Decode(obj)
+}
+
+func (c JSONConfig) newDecoder(r io.Reader) *jsoniter.Decoder {
+	decoder := json.NewDecoder(r)
+	if c.UseNumber {
+		decoder.UseNumber()
+	}
+	if c.DisallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+
+	return decoder
+}
+
+// maxJSONCheckDepth caps the nesting walked by jsonChecker when MaxDepth is
+// not set, the same as the limit of the decoder.
+const maxJSONCheckDepth = 10000
+
+// jsonChecker walks the JSON with the target type to find the path exceeding
+// the limits. The syntax error is left to the decoder.
+type jsonChecker struct {
+	config JSONConfig
+	data   []byte
+	pos    int
+	path   []jsonSegment // of the value being walked, joined only on error
+}
+
+// jsonSegment is a key of object, or an index of array if index >= 0.
+type jsonSegment struct {
+	key   string
+	index int
+}
+
+type jsonSyntaxError struct{}
+
+func (jsonSyntaxError) Error() string {
+	return "invalid json"
+}
+
+func (c *jsonChecker) check(typ reflect.Type) error {
+	err := c.value(typ)
+	if err == nil && c.config.RejectTrailingData {
+		if c.skipSpace(); c.pos < len(c.data) {
+			err = &DecodeError{Err: ErrTrailingData}
+		}
+	}
+	if _, ok := err.(jsonSyntaxError); ok {
+		return nil
+	}
+
+	return err
+}
+
+func (c *jsonChecker) errorf(err error) error {
+	var b strings.Builder
+	for _, s := range c.path {
+		if s.index >= 0 {
+			b.WriteByte('[')
+			b.WriteString(strconv.Itoa(s.index))
+			b.WriteByte(']')
+			continue
+		}
+
+		if b.Len() > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(s.key)
+	}
+
+	return &DecodeError{Path: b.String(), Err: err}
+}
+
+// nest returns an error if the object or array at the current path is too deep.
+func (c *jsonChecker) nest() error {
+	depth := len(c.path) + 1
+	if (c.config.MaxDepth > 0 && depth > c.config.MaxDepth) || depth > maxJSONCheckDepth {
+		return c.errorf(ErrTooDeep)
+	}
+
+	return nil
+}
+
+func (c *jsonChecker) skipSpace() {
+	for c.pos < len(c.data) {
+		switch c.data[c.pos] {
+		case ' ', '\t', '\r', '\n':
+			c.pos++
+		default:
+			return
+		}
+	}
+}
+
+// next returns the next non-space byte, 0 at the end.
+func (c *jsonChecker) next() byte {
+	if c.skipSpace(); c.pos >= len(c.data) {
+		return 0
+	}
+
+	c.pos++
+	return c.data[c.pos-1]
+}
+
+func (c *jsonChecker) value(typ reflect.Type) error {
+	typ = jsonTarget(typ)
+
+	if c.skipSpace(); c.pos >= len(c.data) {
+		return jsonSyntaxError{}
+	}
+
+	switch c.data[c.pos] {
+	case '{':
+		return c.object(typ)
+	case '[':
+		return c.array(typ)
+	case '"':
+		_, err := c.str()
+		return err
+	}
+
+	start := c.pos
+	for c.pos < len(c.data) && !strings.ContainsRune(",]} \t\r\n", rune(c.data[c.pos])) {
+		c.pos++
+	}
+	if c.pos == start {
+		return jsonSyntaxError{}
+	}
+
+	return nil
+}
+
+func (c *jsonChecker) object(typ reflect.Type) error {
+	if err := c.nest(); err != nil {
+		return err
+	}
+	c.pos++ // {
+
+	if c.skipSpace(); c.pos < len(c.data) && c.data[c.pos] == '}' {
+		c.pos++
+		return nil
+	}
+
+	n := len(c.path)
+	defer func() { c.path = c.path[:n] }()
+
+	for {
+		if c.skipSpace(); c.pos >= len(c.data) {
+			return jsonSyntaxError{}
+		}
+		key, err := c.str()
+		if err != nil {
+			return err
+		}
+		if c.next() != ':' {
+			return jsonSyntaxError{}
+		}
+
+		c.path = append(c.path[:n], jsonSegment{key: key, index: -1})
+
+		elem, ok := jsonElem(typ, key)
+		if !ok && c.config.DisallowUnknownFields {
+			return c.errorf(ErrUnknownField)
+		}
+		if err := c.value(elem); err != nil {
+			return err
+		}
+
+		switch c.next() {
+		case ',':
+		case '}':
+			return nil
+		default:
+			return jsonSyntaxError{}
+		}
+	}
+}
+
+func (c *jsonChecker) array(typ reflect.Type) error {
+	if err := c.nest(); err != nil {
+		return err
+	}
+	c.pos++ // [
+
+	if c.skipSpace(); c.pos < len(c.data) && c.data[c.pos] == ']' {
+		c.pos++
+		return nil
+	}
+
+	var elem reflect.Type
+	if typ != nil && (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) {
+		elem = typ.Elem()
+	}
+
+	n := len(c.path)
+	defer func() { c.path = c.path[:n] }()
+
+	for i := 0; ; i++ {
+		c.path = append(c.path[:n], jsonSegment{index: i})
+		if err := c.value(elem); err != nil {
+			return err
+		}
+
+		switch c.next() {
+		case ',':
+		case ']':
+			return nil
+		default:
+			return jsonSyntaxError{}
+		}
+	}
+}
+
+// str reads a string, the escaped one is decoded by json.
+func (c *jsonChecker) str() (string, error) {
+	if c.data[c.pos] != '"' {
+		return "", jsonSyntaxError{}
+	}
+
+	start, escaped := c.pos, false
+	for c.pos++; c.pos < len(c.data); c.pos++ {
+		switch c.data[c.pos] {
+		case '\\':
+			escaped = true
+			c.pos++
+		case '"':
+			c.pos++
+			if !escaped {
+				return string(c.data[start+1 : c.pos-1]), nil
+			}
+
+			var s string
+			if err := json.Unmarshal(c.data[start:c.pos], &s); err != nil {
+				return "", jsonSyntaxError{}
+			}
+			return s, nil
+		}
+	}
+
+	return "", jsonSyntaxError{}
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*ojson.Unmarshaler)(nil)).Elem()
+
+// jsonTarget returns the type to check, nil means anything is allowed.
+func jsonTarget(typ reflect.Type) reflect.Type {
+	for typ != nil {
+		if typ.Implements(jsonUnmarshalerType) || reflect.PtrTo(typ).Implements(jsonUnmarshalerType) {
+			return nil
+		}
+		if typ.Kind() != reflect.Ptr {
+			return typ
+		}
+		typ = typ.Elem()
+	}
+
+	return nil
+}
+
+// jsonElem returns the type of key in typ, false if key is an unknown field.
+func jsonElem(typ reflect.Type, key string) (reflect.Type, bool) {
+	if typ == nil {
+		return nil, true
+	}
+
+	switch typ.Kind() {
+	case reflect.Map:
+		return typ.Elem(), true
+	case reflect.Struct:
+		t, ok := jsonFields(typ)[strings.ToLower(key)]
+		return t, ok
+	}
+
+	return nil, true
+}
+
+var jsonFieldsCache sync.Map // map[reflect.Type]map[string]reflect.Type
+
+// jsonFields returns the fields of struct by the lower case name of json,
+// the fields of embedded struct are included.
+func jsonFields(typ reflect.Type) map[string]reflect.Type {
+	if m, ok := jsonFieldsCache.Load(typ); ok {
+		return m.(map[string]reflect.Type)
+	}
+
+	m := make(map[string]reflect.Type)
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		name, _ := head(f.Tag.Get("json"), ",")
+		if name == "-" && !strings.HasPrefix(f.Tag.Get("json"), "-,") {
+			continue
+		}
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				for k, t := range jsonFields(ft) {
+					if _, ok := m[k]; !ok {
+						m[k] = t
+					}
+				}
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		m[strings.ToLower(name)] = f.Type
+	}
+
+	jsonFieldsCache.Store(typ, m)
+	return m
 }
@@ -36,11 +36,11 @@ func MapForm(obj interface{}, form map[string][]string,
 		return fmt.Errorf("form mapping need map[string]string, map[string][]string or struct")
 	}
 
-	return _mapForm(formStruct, form, formfile, tag)
+	return _mapForm(formStruct, form, formfile, tag, 0, &formBudget{left: MaxFormElements})
 }
 
 func _mapForm(formStruct reflect.Value, form map[string][]string,
-	formfile map[string][]*multipart.FileHeader, tag string) error {
+	formfile map[string][]*multipart.FileHeader, tag string, depth int, budget *formBudget) error {
 	if formStruct.Kind() == reflect.Ptr {
 		formStruct = formStruct.Elem()
 	}
@@ -55,20 +55,22 @@ func _mapForm(formStruct reflect.Value, form map[string][]string,
 		}
 
 		if typeField.Type.Kind() == reflect.Ptr && typeField.Type != multipartFileType {
-			if structField.IsNil() && (typeField.Anonymous || typeField.Type.Elem().Kind() == reflect.Struct) {
+			if structField.IsNil() && (typeField.Anonymous ||
+				(typeField.Type.Elem().Kind() == reflect.Struct && !isUnmarshaler(typeField.Type.Elem()))) {
 				structField.Set(reflect.New(typeField.Type.Elem()))
 
 				structField = structField.Elem()
 			}
 		}
 
-		if (structField.Kind() == reflect.Struct && structField.Type() != timeType) || typeField.Anonymous { // typeField.Anonymous is an embedded field
-			if err := _mapForm(structField, form, formfile, tag); err != nil {
+		if (structField.Kind() == reflect.Struct && structField.Type() != timeType && !isUnmarshaler(structField.Type())) ||
+			typeField.Anonymous { // typeField.Anonymous is an embedded field
+			if err := _mapForm(structField, form, formfile, tag, depth, budget); err != nil {
 				return err
 			}
 		}
 
-		if err := tryToSetValue2(structField, typeField, form, formfile, tag); err != nil {
+		if err := tryToSetValue2(structField, typeField, form, formfile, tag, depth, budget); err != nil {
 			return err
 		}
 	}
@@ -83,7 +85,7 @@ var (
 
 // typeField does't use typeField.Type.Kind(), typeField only form tag in here
 func tryToSetValue2(value reflect.Value, typeField reflect.StructField, form map[string][]string,
-	formfile map[string][]*multipart.FileHeader, tag string) error {
+	formfile map[string][]*multipart.FileHeader, tag string, depth int, budget *formBudget) error {
 
 	bName, bOpt := parseFormName(typeField, tag)
 	if bName == "-" {
@@ -99,7 +101,7 @@ func tryToSetValue2(value reflect.Value, typeField reflect.StructField, form map
 		var err error
 		num := len(inputValue)
 
-		if value.Kind() == reflect.Slice && num > 0 {
+		if value.Kind() == reflect.Slice && num > 0 && !isUnmarshaler(value.Type()) {
 			sliceOf := value.Type().Elem().Kind()
 			slice := reflect.MakeSlice(value.Type(), num, num)
 			for i := 0; i < num; i++ {
@@ -115,6 +117,11 @@ func tryToSetValue2(value reflect.Value, typeField reflect.StructField, form map
 		return err
 	}
 
+	// e.g. items[0][name], meta[tags][] or meta.tags
+	if sub := subForm(form, bName); len(sub) > 0 {
+		return mapNested(value, typeField, bOpt, sub, tag, bName, depth+1, budget)
+	}
+
 	inputFile, existFile := formfile[bName]
 	if !existFile {
 		return nil
@@ -155,6 +162,13 @@ func setWithProperType2(typeField reflect.StructField, opt *setOptions, valueKin
 		valueKind = structField.Kind()
 	}
 
+	if ok, err := trySetUnmarshaler(structField, val); ok {
+		if err != nil {
+			return fmt.Errorf("can't set %s with %s: %w", nameInTag, val, err)
+		}
+		return nil
+	}
+
 	switch valueKind {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		if val == "" {
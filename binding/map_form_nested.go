@@ -0,0 +1,235 @@
+package binding
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Limits of the nested form keys, e.g. items[0][name]=a, meta[tags][]=x or meta.tags=x.
+var (
+	// MaxFormDepth is the max nesting of a form key.
+	MaxFormDepth = 32
+	// MaxFormElements is the max elements of all slices and maps decoded from
+	// the nested keys of a form, an index of slice must be less than it.
+	MaxFormElements = 1000
+
+	ErrFormTooDeep         = errors.New("binding: form key is nested too deep")
+	ErrFormTooManyElements = errors.New("binding: form has too many elements")
+)
+
+// formBudget is the elements left for the nested slices and maps of a MapForm call,
+// so the nested indexes can't multiply the allocation.
+type formBudget struct {
+	left int
+}
+
+func (b *formBudget) take(n int, path string) error {
+	if n > b.left {
+		return fmt.Errorf("%w: %s", ErrFormTooManyElements, path)
+	}
+	b.left -= n
+
+	return nil
+}
+
+// subForm returns the values of form under name, keyed by the rest of key:
+// "[0][name]", ".tags", "[]".
+func subForm(form map[string][]string, name string) map[string][]string {
+	var sub map[string][]string
+
+	for k, vs := range form {
+		if len(k) <= len(name) || !strings.HasPrefix(k, name) {
+			continue
+		}
+		if rest := k[len(name):]; rest[0] == '[' || rest[0] == '.' {
+			if sub == nil {
+				sub = make(map[string][]string)
+			}
+			sub[rest] = vs
+		}
+	}
+
+	return sub
+}
+
+// splitFormKey splits "[a][b]" or ".a.b" to "a" and "[b]" or ".b".
+func splitFormKey(key string) (seg, tail string, ok bool) {
+	switch {
+	case strings.HasPrefix(key, "["):
+		i := strings.IndexByte(key, ']')
+		if i < 0 {
+			return "", "", false
+		}
+		return key[1:i], key[i+1:], true
+	case strings.HasPrefix(key, "."):
+		key = key[1:]
+		if i := strings.IndexAny(key, ".["); i >= 0 {
+			return key[:i], key[i:], true
+		}
+		return key, "", true
+	}
+
+	return "", "", false
+}
+
+// groupForm groups the values of form by the first segment of key,
+// the values of "" key are the value itself.
+func groupForm(form map[string][]string) (map[string]map[string][]string, error) {
+	groups := make(map[string]map[string][]string)
+
+	for k, vs := range form {
+		seg, tail, ok := splitFormKey(k)
+		if !ok {
+			continue
+		}
+
+		g := groups[seg]
+		if g == nil {
+			if len(groups) >= MaxFormElements {
+				return nil, ErrFormTooManyElements
+			}
+			g = make(map[string][]string)
+			groups[seg] = g
+		}
+		g[tail] = vs
+	}
+
+	return groups, nil
+}
+
+// mapNested sets value by form whose keys are relative to value, see subForm.
+func mapNested(value reflect.Value, typeField reflect.StructField, opt *setOptions,
+	form map[string][]string, tag, path string, depth int, budget *formBudget) error {
+	if depth > MaxFormDepth {
+		return fmt.Errorf("%w: %s", ErrFormTooDeep, path)
+	}
+
+	if value.Kind() == reflect.Ptr && value.Type() != multipartFileType {
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		if value.Type() == timeType || isUnmarshaler(value.Type()) || (len(form) == 1 && form[""] != nil) {
+			break
+		}
+
+		fields := make(map[string][]string, len(form))
+		for k, vs := range form {
+			if seg, tail, ok := splitFormKey(k); ok && seg != "" {
+				fields[seg+tail] = vs
+			}
+		}
+
+		return _mapForm(value, fields, nil, tag, depth, budget)
+	case reflect.Slice:
+		return mapNestedSlice(value, typeField, opt, form, tag, path, depth, budget)
+	case reflect.Map:
+		return mapNestedMap(value, typeField, opt, form, tag, path, depth, budget)
+	}
+
+	if vs := form[""]; len(vs) > 0 {
+		return setWithProperType2(typeField, opt, value.Kind(), vs[0], value, path)
+	}
+
+	return nil
+}
+
+// mapNestedSlice sets items[0][name]=a, items[1][name]=b and tags[]=x, tags[]=y.
+// The elements of "[]" are appended after the indexed ones.
+func mapNestedSlice(value reflect.Value, typeField reflect.StructField, opt *setOptions,
+	form map[string][]string, tag, path string, depth int, budget *formBudget) error {
+	var appended []string
+	if vs, ok := form[""]; ok {
+		appended = append(appended, vs...)
+	}
+
+	groups, err := groupForm(form)
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, path)
+	}
+	if g, ok := groups[""]; ok {
+		appended = append(appended, g[""]...)
+		delete(groups, "")
+	}
+
+	indexes := make([]int, 0, len(groups))
+	for seg := range groups {
+		i, err := strconv.Atoi(seg)
+		if err != nil || i < 0 {
+			return fmt.Errorf("%s[%s] is not a slice index", path, seg)
+		}
+		if i >= MaxFormElements {
+			return fmt.Errorf("%w: %s[%d]", ErrFormTooManyElements, path, i)
+		}
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	n := len(appended)
+	if len(indexes) > 0 {
+		n += indexes[len(indexes)-1] + 1
+	}
+	if err := budget.take(n, path); err != nil {
+		return err
+	}
+
+	slice := reflect.MakeSlice(value.Type(), n, n)
+	for _, i := range indexes {
+		name := path + "[" + strconv.Itoa(i) + "]"
+		if err := mapNested(slice.Index(i), typeField, opt, groups[strconv.Itoa(i)], tag, name, depth+1, budget); err != nil {
+			return err
+		}
+	}
+	for i, v := range appended {
+		j := n - len(appended) + i
+		name := path + "[" + strconv.Itoa(j) + "]"
+		if err := mapNested(slice.Index(j), typeField, opt, map[string][]string{"": {v}}, tag, name, depth+1, budget); err != nil {
+			return err
+		}
+	}
+	value.Set(slice)
+
+	return nil
+}
+
+// mapNestedMap sets meta[a]=1, meta[tags][]=x and meta.b=2, the key of map must be string.
+func mapNestedMap(value reflect.Value, typeField reflect.StructField, opt *setOptions,
+	form map[string][]string, tag, path string, depth int, budget *formBudget) error {
+	typ := value.Type()
+	if typ.Key().Kind() != reflect.String {
+		return fmt.Errorf("%s: map key must be string", path)
+	}
+
+	groups, err := groupForm(form)
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, path)
+	}
+	if err := budget.take(len(groups), path); err != nil {
+		return err
+	}
+
+	if value.IsNil() {
+		value.Set(reflect.MakeMapWithSize(typ, len(groups)))
+	}
+	for seg, g := range groups {
+		if seg == "" {
+			continue
+		}
+
+		elem := reflect.New(typ.Elem()).Elem()
+		if err := mapNested(elem, typeField, opt, g, tag, path+"["+seg+"]", depth+1, budget); err != nil {
+			return err
+		}
+		value.SetMapIndex(reflect.ValueOf(seg).Convert(typ.Key()), elem)
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package binding
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mapNestedForm(obj interface{}, query string) error {
+	form, err := url.ParseQuery(query)
+	if err != nil {
+		return err
+	}
+
+	return MapForm(obj, form, nil, "form")
+}
+
+func TestMapFormNestedSliceOfStruct(t *testing.T) {
+	type item struct {
+		Name string   `form:"name"`
+		Qty  int      `form:"qty"`
+		Tags []string `form:"tags"`
+	}
+	var s struct {
+		Items []item  `form:"items"`
+		Ptrs  []*item `form:"ptrs"`
+	}
+
+	err := mapNestedForm(&s, "items[0][name]=a&items[0][qty]=2&items[1][name]=b&items[1][tags][]=x&items[1][tags][]=y&ptrs[0].name=c")
+	assert.NoError(t, err)
+	assert.Equal(t, []item{
+		{Name: "a", Qty: 2},
+		{Name: "b", Tags: []string{"x", "y"}},
+	}, s.Items)
+	assert.Len(t, s.Ptrs, 1)
+	assert.Equal(t, "c", s.Ptrs[0].Name)
+}
+
+func TestMapFormNestedSliceIndex(t *testing.T) {
+	var s struct {
+		Ids []int `form:"ids"`
+	}
+
+	for _, tt := range []struct {
+		query  string
+		expect []int
+	}{
+		{"ids[]=1&ids[]=2", []int{1, 2}},
+		{"ids[1]=2&ids[0]=1", []int{1, 2}},
+		{"ids[2]=3", []int{0, 0, 3}},
+		{"ids=1&ids=2&ids[]=3", []int{1, 2}}, // the plain key wins
+	} {
+		s.Ids = nil
+		assert.NoError(t, mapNestedForm(&s, tt.query), tt.query)
+		assert.Equal(t, tt.expect, s.Ids, tt.query)
+	}
+
+	assert.Error(t, mapNestedForm(&s, "ids[a]=1"))
+	assert.Error(t, mapNestedForm(&s, "ids[0]=a"))
+}
+
+func TestMapFormNestedMap(t *testing.T) {
+	var s struct {
+		Meta  map[string][]string       `form:"meta"`
+		Attrs map[string]int            `form:"attrs"`
+		Deep  map[string]map[string]int `form:"deep"`
+	}
+
+	err := mapNestedForm(&s, "meta[tags][]=x&meta[tags][]=y&meta.owner=z&attrs[a]=1&attrs.b=2&deep[a][b]=3")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{"tags": {"x", "y"}, "owner": {"z"}}, s.Meta)
+	assert.Equal(t, map[string]int{"a": 1, "b": 2}, s.Attrs)
+	assert.Equal(t, map[string]map[string]int{"a": {"b": 3}}, s.Deep)
+
+	var bad struct {
+		M map[int]string `form:"m"`
+	}
+	assert.Error(t, mapNestedForm(&bad, "m[1]=a"))
+}
+
+func TestMapFormNestedStructAndPointer(t *testing.T) {
+	type address struct {
+		City string    `form:"city"`
+		At   time.Time `form:"at" time_format:"2006-01-02"`
+	}
+	var s struct {
+		Home  address  `form:"home"`
+		Work  *address `form:"work"`
+		Level *int     `form:"level"`
+		City  string   `form:"city"`
+	}
+
+	err := mapNestedForm(&s, "home[city]=a&home[at]=2020-01-02&work.city=b&city=c")
+	assert.NoError(t, err)
+	assert.Equal(t, "a", s.Home.City)
+	assert.Equal(t, 2020, s.Home.At.Year())
+	assert.Equal(t, "b", s.Work.City)
+	assert.Nil(t, s.Level)
+	assert.Equal(t, "c", s.City)
+}
+
+func TestMapFormNestedLimits(t *testing.T) {
+	var s struct {
+		Ids []int `form:"ids"`
+	}
+
+	err := mapNestedForm(&s, "ids[100000000]=1")
+	assert.ErrorIs(t, err, ErrFormTooManyElements)
+
+	var q []string
+	for i := 0; i <= MaxFormElements; i++ {
+		q = append(q, "ids[]=1")
+	}
+	err = mapNestedForm(&s, strings.Join(q, "&"))
+	assert.ErrorIs(t, err, ErrFormTooManyElements)
+
+	// the elements of all nested slices share the limit
+	var m struct {
+		Items [][]int `form:"items"`
+	}
+	q = q[:0]
+	for i := 0; i < 10; i++ {
+		q = append(q, fmt.Sprintf("items[%d][999]=1", i))
+	}
+	err = mapNestedForm(&m, strings.Join(q, "&"))
+	assert.ErrorIs(t, err, ErrFormTooManyElements)
+	assert.NoError(t, mapNestedForm(&m, "items[0][99]=1&items[1][99]=1"))
+
+	type c struct {
+		Name string `form:"name"`
+	}
+	type b struct {
+		C c `form:"c"`
+	}
+	var a struct {
+		B *b `form:"b"`
+	}
+	err = mapNestedForm(&a, "b[c][name]=x")
+	assert.NoError(t, err)
+	assert.Equal(t, "x", a.B.C.Name)
+
+	defer func(n int) { MaxFormDepth = n }(MaxFormDepth)
+	MaxFormDepth = 1
+	err = mapNestedForm(&a, "b.c.name=x")
+	assert.ErrorIs(t, err, ErrFormTooDeep)
+}
@@ -121,7 +121,12 @@ func setByForm(value reflect.Value, field reflect.StructField, form map[string][
 		return false, nil
 	}
 
-	switch value.Kind() {
+	kind := value.Kind()
+	if isUnmarshaler(value.Type()) { // e.g. net.IP is a slice
+		kind = reflect.String
+	}
+
+	switch kind {
 	case reflect.Slice:
 		if !ok {
 			vs = []string{opt.defaultValue}
@@ -290,6 +295,17 @@ func setArray(vals []string, value reflect.Value, field reflect.StructField) err
 }
 
 func setWithProperType(val string, value reflect.Value, field reflect.StructField) error {
+	if value.Kind() == reflect.Ptr { // e.g. the element of []*T
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		return setWithProperType(val, value.Elem(), field)
+	}
+
+	if ok, err := trySetUnmarshaler(value, val); ok {
+		return err
+	}
+
 	switch value.Kind() {
 	case reflect.Int:
 		return setIntField(val, 0, value)
@@ -0,0 +1,48 @@
+//go:build !nomsgpack
+// +build !nomsgpack
+
+package binding
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ugorji/go/codec"
+)
+
+// go build -tags nomsgpack, to remove the MsgPack binding and its dependency
+var (
+	MsgPack = msgpackBinding{}
+
+	msgpackHandle codec.MsgpackHandle
+)
+
+func init() {
+	Register(MIMEMSGPACK, MsgPack)
+	Register(MIMEMSGPACK2, MsgPack)
+}
+
+type msgpackBinding struct{}
+
+func (msgpackBinding) Name() string {
+	return "msgpack"
+}
+
+func (b msgpackBinding) Bind(req *http.Request, obj interface{}) error {
+	if err := b.Decode(req, obj); err != nil {
+		return err
+	}
+
+	return validate(obj)
+}
+
+func (b msgpackBinding) Decode(req *http.Request, obj interface{}) error {
+	if req == nil || req.Body == nil {
+		return fmt.Errorf("invalid request for bind %s", b.Name())
+	}
+	defer req.Body.Close()
+
+	body := &bodyReader{Reader: req.Body}
+	decoder := codec.NewDecoder(body, &msgpackHandle)
+	return body.check(decoder.Decode(obj))
+}
@@ -0,0 +1,25 @@
+//go:build !nomsgpack
+// +build !nomsgpack
+
+package binding
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ugorji/go/codec"
+)
+
+func TestBindingMsgPack(t *testing.T) {
+	test := FooStruct{Foo: "bar"}
+
+	var buf bytes.Buffer
+	assert.NoError(t, codec.NewEncoder(&buf, &msgpackHandle).Encode(test))
+
+	assert.Equal(t, MsgPack, NewBindinger("POST", MIMEMSGPACK2))
+	testBodyBinding(t,
+		MsgPack, "msgpack",
+		"/", "/",
+		buf.String(), string(buf.Bytes()[1:]))
+}
@@ -17,9 +17,13 @@ func (queryBinding) Bind2(req *http.Request, obj interface{}) error {
 	return validate(obj)
 }
 
-func (queryBinding) Bind(req *http.Request, obj interface{}) error {
-	if err := MapForm(obj, req.URL.Query(), nil, "form"); err != nil {
+func (b queryBinding) Bind(req *http.Request, obj interface{}) error {
+	if err := b.Decode(req, obj); err != nil {
 		return err
 	}
 	return validate(obj)
 }
+
+func (queryBinding) Decode(req *http.Request, obj interface{}) error {
+	return MapForm(obj, req.URL.Query(), nil, "form")
+}
@@ -0,0 +1,84 @@
+package binding
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Bindinger{
+		MIMEJSON:              JSON,
+		MIMEXML:               XML,
+		MIMEXML2:              XML,
+		MIMEPOSTForm:          Form,
+		MIMEMultipartPOSTForm: FormMultipart,
+		MIMEYAML:              YAML,
+		MIMEYAML2:             YAML,
+	}
+)
+
+// Register makes b the Bindinger of mediaType, which can be "type/subtype",
+// "type/*" or "*/*". A nil b removes the registration.
+// b must be a Decoder too, so that the body can be merged with the other
+// sources before validation. It panics if mediaType is invalid or b is not a Decoder.
+//
+//	binding.Register("application/vnd.foo", fooBinding{})
+//	binding.Register("text/*", textBinding{})
+func Register(mediaType string, b Bindinger) {
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	if i := strings.IndexByte(mediaType, '/'); i <= 0 || i == len(mediaType)-1 ||
+		(mediaType[:i] == "*" && mediaType != "*/*") {
+		panic("binding: invalid media type " + mediaType)
+	}
+
+	if _, ok := b.(Decoder); b != nil && !ok {
+		panic(fmt.Sprintf("binding: %s of %s is not a Decoder", b.Name(), mediaType))
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if b == nil {
+		delete(registry, mediaType)
+		return
+	}
+	registry[mediaType] = b
+}
+
+// Lookup returns the Bindinger of mediaType, nil if there is none. It tries
+// in order:
+//   - the exact media type, e.g. "application/vnd.api+json"
+//   - the structured syntax suffix, e.g. "+json" as "application/json"
+//   - the wildcard of type, e.g. "application/*"
+//   - "*/*"
+func Lookup(mediaType string) Bindinger {
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	if i := strings.IndexByte(mediaType, ';'); i >= 0 {
+		mediaType = strings.TrimSpace(mediaType[:i])
+	}
+
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	if b, ok := registry[mediaType]; ok {
+		return b
+	}
+
+	typ, sub, ok := strings.Cut(mediaType, "/")
+	if !ok {
+		return nil
+	}
+
+	if i := strings.LastIndexByte(sub, '+'); i >= 0 {
+		if b, ok := registry["application/"+sub[i+1:]]; ok {
+			return b
+		}
+	}
+	if b, ok := registry[typ+"/*"]; ok {
+		return b
+	}
+
+	return registry["*/*"]
+}
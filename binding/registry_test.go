@@ -0,0 +1,61 @@
+package binding
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryLookup(t *testing.T) {
+	assert.Equal(t, JSON, Lookup("Application/JSON; charset=utf-8"))
+	assert.Equal(t, JSON, Lookup("application/vnd.api+json"))
+	assert.Equal(t, JSON, Lookup("application/merge-patch+json"))
+	assert.Equal(t, XML, Lookup("application/soap+xml"))
+	assert.Nil(t, Lookup("application/vnd.foo"))
+	assert.Nil(t, Lookup("invalid"))
+
+	Register("application/vnd.foo", XML)
+	Register("text/*", YAML)
+	defer Register("application/vnd.foo", nil)
+	defer Register("text/*", nil)
+
+	assert.Equal(t, XML, Lookup("application/vnd.foo"))
+	assert.Equal(t, YAML, Lookup("text/plain"))
+	assert.Equal(t, XML, Lookup("text/xml")) // the exact one wins
+	assert.Nil(t, Lookup("image/png"))
+
+	Register("*/*", Form)
+	defer Register("*/*", nil)
+	assert.Equal(t, Form, Lookup("image/png"))
+
+	assert.Panics(t, func() { Register("json", JSON) })
+	assert.Panics(t, func() { Register("*/json", JSON) })
+	assert.Panics(t, func() { Register("application/vnd.foo", bindOnly{}) })
+}
+
+func TestBindingUnsupportedMediaType(t *testing.T) {
+	var obj FooStruct
+	req := requestWithBody("POST", "/", "foo")
+	err := NewBindinger("POST", "image/png").Bind(req, &obj)
+
+	var e *UnsupportedMediaTypeError
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, "image/png", e.MediaType)
+	assert.Equal(t, http.StatusUnsupportedMediaType, e.StatusCode())
+}
+
+func TestBindingYAML(t *testing.T) {
+	testBodyBinding(t,
+		YAML, "yaml",
+		"/", "/",
+		"foo: bar", "bar: foo")
+}
+
+// bindOnly is a Bindinger which is not a Decoder.
+type bindOnly struct{}
+
+func (bindOnly) Name() string { return "bind-only" }
+
+func (bindOnly) Bind(*http.Request, interface{}) error { return nil }
@@ -0,0 +1,61 @@
+//go:build !notoml
+// +build !notoml
+
+package binding
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// go build -tags notoml, to remove the TOML binding
+var (
+	TOML = tomlBinding{}
+
+	// tomlJSON maps the parsed TOML document to obj by the "toml" tag.
+	tomlJSON = jsoniter.Config{TagKey: "toml"}.Froze()
+)
+
+func init() {
+	Register(MIMETOML, TOML)
+}
+
+type tomlBinding struct{}
+
+func (tomlBinding) Name() string {
+	return "toml"
+}
+
+func (b tomlBinding) Bind(req *http.Request, obj interface{}) error {
+	if err := b.Decode(req, obj); err != nil {
+		return err
+	}
+
+	return validate(obj)
+}
+
+// Decode supports a subset of TOML v1.0.0, see parseTOML.
+func (b tomlBinding) Decode(req *http.Request, obj interface{}) error {
+	if req == nil || req.Body == nil {
+		return fmt.Errorf("invalid request for bind %s", b.Name())
+	}
+	defer req.Body.Close()
+
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		return BodyError(err)
+	}
+
+	doc, err := parseTOML(string(data))
+	if err != nil {
+		return err
+	}
+
+	if data, err = tomlJSON.Marshal(doc); err != nil {
+		return err
+	}
+	return tomlJSON.Unmarshal(data, obj)
+}
@@ -0,0 +1,559 @@
+//go:build !notoml
+// +build !notoml
+
+package binding
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// parseTOML parses the subset of TOML v1.0.0 used by configs and requests:
+//   - bare, quoted and dotted keys
+//   - [table] and [[array of tables]]
+//   - basic, literal and multi-line strings
+//   - integers(decimal, hex, octal, binary), floats except inf and nan, booleans
+//   - offset date-time as time.Time, local date-time, date and time as string
+//   - arrays and inline tables
+//
+// Redefining a table is allowed, redefining a key is not.
+// Nesting deeper than MaxTOMLDepth returns *DecodeError of ErrTooDeep.
+func parseTOML(s string) (map[string]interface{}, error) {
+	p := &tomlParser{s: s}
+	root := make(map[string]interface{})
+
+	cur := root
+	for {
+		p.skipBlank(true)
+		if p.eof() {
+			return root, nil
+		}
+
+		var err error
+		if p.peek() == '[' {
+			cur, err = p.parseTable(root)
+		} else {
+			err = p.parseKeyValue(cur)
+		}
+		if err == nil {
+			err = p.expectLineEnd()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// MaxTOMLDepth is the max nesting of arrays and inline tables, and the max
+// parts of a dotted key.
+var MaxTOMLDepth = 100
+
+type tomlParser struct {
+	s     string
+	pos   int
+	depth int
+	keys  []string // path of the value being parsed
+}
+
+// nest enters an array or inline table, the caller must call unnest after it.
+func (p *tomlParser) nest() error {
+	if p.depth++; p.depth > MaxTOMLDepth {
+		return &DecodeError{Path: strings.Join(p.keys, "."), Err: ErrTooDeep}
+	}
+
+	return nil
+}
+
+func (p *tomlParser) unnest() {
+	p.depth--
+}
+
+func (p *tomlParser) errorf(format string, args ...interface{}) error {
+	line := strings.Count(p.s[:p.pos], "\n") + 1
+	return fmt.Errorf("toml: line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+func (p *tomlParser) eof() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *tomlParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func (p *tomlParser) consume(prefix string) bool {
+	if strings.HasPrefix(p.s[p.pos:], prefix) {
+		p.pos += len(prefix)
+		return true
+	}
+	return false
+}
+
+// skipBlank skips spaces, tabs and comments, and newlines if newline is true.
+func (p *tomlParser) skipBlank(newline bool) {
+	for !p.eof() {
+		switch c := p.peek(); {
+		case c == ' ' || c == '\t':
+			p.pos++
+		case c == '#':
+			for !p.eof() && p.peek() != '\n' {
+				p.pos++
+			}
+		case newline && (c == '\n' || c == '\r'):
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *tomlParser) expectLineEnd() error {
+	p.skipBlank(false)
+	if p.eof() || p.consume("\n") || p.consume("\r\n") {
+		return nil
+	}
+
+	return p.errorf("unexpected %q after value", p.peek())
+}
+
+// parseTable parses [a.b] or [[a.b]], returns the table to put the following keys.
+func (p *tomlParser) parseTable(root map[string]interface{}) (map[string]interface{}, error) {
+	array := p.consume("[[")
+	if !array {
+		p.pos++
+	}
+
+	p.skipBlank(false)
+	keys, err := p.parseKey()
+	if err != nil {
+		return nil, err
+	}
+	p.skipBlank(false)
+
+	if array {
+		if !p.consume("]]") {
+			return nil, p.errorf("expected ]]")
+		}
+	} else if !p.consume("]") {
+		return nil, p.errorf("expected ]")
+	}
+
+	parent, err := p.descend(root, keys[:len(keys)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := keys[len(keys)-1]
+
+	if array {
+		var tables []map[string]interface{}
+		switch v := parent[last].(type) {
+		case nil:
+		case []map[string]interface{}:
+			tables = v
+		default:
+			return nil, p.errorf("key %q is not an array of tables", last)
+		}
+
+		t := make(map[string]interface{})
+		parent[last] = append(tables, t)
+		return t, nil
+	}
+
+	return p.descend(parent, keys[len(keys)-1:])
+}
+
+// descend returns the table of keys in m, creates the missing ones.
+// The last table of an array of tables is used.
+func (p *tomlParser) descend(m map[string]interface{}, keys []string) (map[string]interface{}, error) {
+	for _, k := range keys {
+		switch v := m[k].(type) {
+		case nil:
+			t := make(map[string]interface{})
+			m[k] = t
+			m = t
+		case map[string]interface{}:
+			m = v
+		case []map[string]interface{}:
+			m = v[len(v)-1]
+		default:
+			return nil, p.errorf("key %q is not a table", k)
+		}
+	}
+
+	return m, nil
+}
+
+func (p *tomlParser) parseKeyValue(m map[string]interface{}) error {
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+
+	p.skipBlank(false)
+	if !p.consume("=") {
+		return p.errorf("expected = after key")
+	}
+	p.skipBlank(false)
+
+	n := len(p.keys)
+	p.keys = append(p.keys, keys...)
+	v, err := p.parseValue()
+	p.keys = p.keys[:n]
+	if err != nil {
+		return err
+	}
+
+	if m, err = p.descend(m, keys[:len(keys)-1]); err != nil {
+		return err
+	}
+	last := keys[len(keys)-1]
+	if _, ok := m[last]; ok {
+		return p.errorf("duplicate key %q", last)
+	}
+	m[last] = v
+
+	return nil
+}
+
+// parseKey parses a, "a b", 'a' and a.b."c".
+func (p *tomlParser) parseKey() ([]string, error) {
+	var keys []string
+
+	for {
+		p.skipBlank(false)
+
+		var k string
+		var err error
+		switch p.peek() {
+		case '"':
+			k, err = p.parseBasicString()
+		case '\'':
+			k, err = p.parseLiteralString()
+		default:
+			start := p.pos
+			for !p.eof() && isTOMLBareKey(p.peek()) {
+				p.pos++
+			}
+			if k = p.s[start:p.pos]; k == "" {
+				return nil, p.errorf("invalid key")
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+		if len(keys) > MaxTOMLDepth {
+			return nil, &DecodeError{Path: strings.Join(keys, "."), Err: ErrTooDeep}
+		}
+
+		p.skipBlank(false)
+		if !p.consume(".") {
+			return keys, nil
+		}
+	}
+}
+
+func isTOMLBareKey(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+func (p *tomlParser) parseValue() (interface{}, error) {
+	switch {
+	case strings.HasPrefix(p.s[p.pos:], `"""`):
+		return p.parseMultilineString(`"""`)
+	case strings.HasPrefix(p.s[p.pos:], `'''`):
+		return p.parseMultilineString(`'''`)
+	case p.peek() == '"':
+		return p.parseBasicString()
+	case p.peek() == '\'':
+		return p.parseLiteralString()
+	case p.peek() == '[':
+		return p.parseArray()
+	case p.peek() == '{':
+		return p.parseInlineTable()
+	case p.consume("true"):
+		return true, nil
+	case p.consume("false"):
+		return false, nil
+	}
+
+	return p.parseScalar()
+}
+
+func (p *tomlParser) parseBasicString() (string, error) {
+	p.pos++ // "
+
+	var b strings.Builder
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", p.errorf("unterminated string")
+		}
+
+		switch c := p.s[p.pos]; c {
+		case '"':
+			p.pos++
+			return b.String(), nil
+		case '\\':
+			if err := p.parseEscape(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+func (p *tomlParser) parseEscape(b *strings.Builder) error {
+	p.pos++ // \
+	if p.eof() {
+		return p.errorf("unterminated string")
+	}
+
+	c := p.s[p.pos]
+	p.pos++
+
+	switch c {
+	case 'b':
+		b.WriteByte('\b')
+	case 't':
+		b.WriteByte('\t')
+	case 'n':
+		b.WriteByte('\n')
+	case 'f':
+		b.WriteByte('\f')
+	case 'r':
+		b.WriteByte('\r')
+	case '"':
+		b.WriteByte('"')
+	case '\\':
+		b.WriteByte('\\')
+	case 'u', 'U':
+		n := 4
+		if c == 'U' {
+			n = 8
+		}
+		if p.pos+n > len(p.s) {
+			return p.errorf("invalid unicode escape")
+		}
+		r, err := strconv.ParseUint(p.s[p.pos:p.pos+n], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(r)) {
+			return p.errorf("invalid unicode escape")
+		}
+		b.WriteRune(rune(r))
+		p.pos += n
+	default:
+		return p.errorf("invalid escape \\%c", c)
+	}
+
+	return nil
+}
+
+func (p *tomlParser) parseLiteralString() (string, error) {
+	p.pos++ // '
+
+	start := p.pos
+	for !p.eof() && p.peek() != '\'' {
+		if p.peek() == '\n' {
+			return "", p.errorf("unterminated string")
+		}
+		p.pos++
+	}
+	if p.eof() {
+		return "", p.errorf("unterminated string")
+	}
+	p.pos++
+
+	return p.s[start : p.pos-1], nil
+}
+
+// parseMultilineString parses the multi-line basic and literal strings, the newline right after
+// the opening delimiter is trimmed.
+func (p *tomlParser) parseMultilineString(delim string) (string, error) {
+	p.pos += len(delim)
+	if !p.consume("\n") {
+		p.consume("\r\n")
+	}
+
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated string")
+		}
+		if p.consume(delim) {
+			// up to 2 quotes are allowed right before the closing delimiter
+			for i := 0; i < 2 && p.peek() == delim[0]; i++ {
+				b.WriteByte(delim[0])
+				p.pos++
+			}
+			return b.String(), nil
+		}
+
+		c := p.s[p.pos]
+		if c != '\\' || delim == `'''` {
+			b.WriteByte(c)
+			p.pos++
+			continue
+		}
+
+		// line ending backslash trims the following whitespaces and newlines
+		rest := strings.TrimLeft(p.s[p.pos+1:], " \t")
+		if strings.HasPrefix(rest, "\n") || strings.HasPrefix(rest, "\r\n") {
+			p.pos = len(p.s) - len(strings.TrimLeft(rest, " \t\r\n"))
+			continue
+		}
+		if err := p.parseEscape(&b); err != nil {
+			return "", err
+		}
+	}
+}
+
+func (p *tomlParser) parseArray() ([]interface{}, error) {
+	if err := p.nest(); err != nil {
+		return nil, err
+	}
+	defer p.unnest()
+	p.pos++ // [
+
+	vs := make([]interface{}, 0)
+	for {
+		p.skipBlank(true)
+		if p.consume("]") {
+			return vs, nil
+		}
+
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		vs = append(vs, v)
+
+		p.skipBlank(true)
+		if p.consume("]") {
+			return vs, nil
+		}
+		if !p.consume(",") {
+			return nil, p.errorf("expected , or ] in array")
+		}
+	}
+}
+
+func (p *tomlParser) parseInlineTable() (map[string]interface{}, error) {
+	if err := p.nest(); err != nil {
+		return nil, err
+	}
+	defer p.unnest()
+	p.pos++ // {
+
+	m := make(map[string]interface{})
+	p.skipBlank(false)
+	if p.consume("}") {
+		return m, nil
+	}
+
+	for {
+		if err := p.parseKeyValue(m); err != nil {
+			return nil, err
+		}
+
+		p.skipBlank(false)
+		if p.consume("}") {
+			return m, nil
+		}
+		if !p.consume(",") {
+			return nil, p.errorf("expected , or } in inline table")
+		}
+	}
+}
+
+// parseScalar parses the number and date-time.
+func (p *tomlParser) parseScalar() (interface{}, error) {
+	start := p.pos
+	for !p.eof() && !strings.ContainsRune(" \t\r\n,]}#", rune(p.peek())) {
+		p.pos++
+	}
+	// 1979-05-27 07:32:00Z, the space separates the date and time
+	if p.pos-start == 10 && p.peek() == ' ' && p.pos+1 < len(p.s) && p.s[p.pos+1] >= '0' && p.s[p.pos+1] <= '9' {
+		p.pos++
+		for !p.eof() && !strings.ContainsRune(" \t\r\n,]}#", rune(p.peek())) {
+			p.pos++
+		}
+	}
+
+	tok := p.s[start:p.pos]
+	if tok == "" {
+		return nil, p.errorf("missing value")
+	}
+
+	if v, ok := parseTOMLDateTime(tok); ok {
+		return v, nil
+	}
+	// the document is bound through JSON, which has no inf and nan
+	if t := strings.TrimLeft(tok, "+-"); t == "inf" || t == "nan" {
+		return nil, p.errorf("%s is not supported", tok)
+	}
+	if v, ok := parseTOMLNumber(tok); ok {
+		return v, nil
+	}
+
+	p.pos = start
+	return nil, p.errorf("invalid value %q", tok)
+}
+
+func parseTOMLDateTime(tok string) (interface{}, bool) {
+	if len(tok) < 8 || !(tok[4] == '-' || tok[2] == ':') {
+		return nil, false
+	}
+
+	if len(tok) > 10 && (tok[10] == ' ' || tok[10] == 't') {
+		tok = tok[:10] + "T" + tok[11:]
+	}
+	if t, err := time.Parse(time.RFC3339Nano, strings.ToUpper(tok)); err == nil {
+		return t, true
+	}
+
+	for _, layout := range []string{"2006-01-02T15:04:05.999999999", "2006-01-02", "15:04:05.999999999"} {
+		if _, err := time.Parse(layout, tok); err == nil {
+			return tok, true
+		}
+	}
+
+	return nil, false
+}
+
+func parseTOMLNumber(tok string) (interface{}, bool) {
+	if strings.Contains(tok, "__") || strings.HasPrefix(tok, "_") || strings.HasSuffix(tok, "_") {
+		return nil, false
+	}
+	tok = strings.ReplaceAll(tok, "_", "")
+
+	if len(tok) > 2 && tok[0] == '0' {
+		base := 0
+		switch tok[1] {
+		case 'x':
+			base = 16
+		case 'o':
+			base = 8
+		case 'b':
+			base = 2
+		}
+		if base != 0 {
+			n, err := strconv.ParseInt(tok[2:], base, 64)
+			return n, err == nil
+		}
+	}
+
+	if strings.ContainsAny(tok, ".eE") {
+		f, err := strconv.ParseFloat(tok, 64)
+		return f, err == nil
+	}
+
+	n, err := strconv.ParseInt(tok, 10, 64)
+	return n, err == nil
+}
@@ -0,0 +1,152 @@
+//go:build !notoml
+// +build !notoml
+
+package binding
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTOML(t *testing.T) {
+	doc, err := parseTOML(`
+# comment
+title = "a\tb \u6C34"
+"quoted key" = 'C:\path'
+a.b.c = 1
+hex = 0xff
+oct = 0o17
+bin = 0b101
+big = 1_000
+neg = -3
+exp = 5e+2
+yes = true
+odt = 1979-05-27 07:32:00Z
+ld = 1979-05-27
+lt = 07:32:00
+ml = """
+one \
+   two"""
+lit = '''
+raw\n'''
+arr = [[1, 2], ["x"]]
+inline = { x = 1, y.z = "w" }
+
+[server.http]
+port = 8080
+
+[[fruits]]
+name = "apple"
+[fruits.color]
+value = "red"
+[[fruits]]
+name = "banana"
+`)
+	assert.NoError(t, err)
+	assert.Equal(t, "a\tb 水", doc["title"])
+	assert.Equal(t, `C:\path`, doc["quoted key"])
+	assert.Equal(t, map[string]interface{}{"b": map[string]interface{}{"c": int64(1)}}, doc["a"])
+	assert.Equal(t, int64(255), doc["hex"])
+	assert.Equal(t, int64(15), doc["oct"])
+	assert.Equal(t, int64(5), doc["bin"])
+	assert.Equal(t, int64(1000), doc["big"])
+	assert.Equal(t, int64(-3), doc["neg"])
+	assert.Equal(t, 500.0, doc["exp"])
+	assert.Equal(t, true, doc["yes"])
+	assert.Equal(t, time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC), doc["odt"])
+	assert.Equal(t, "1979-05-27", doc["ld"])
+	assert.Equal(t, "07:32:00", doc["lt"])
+	assert.Equal(t, "one two", doc["ml"])
+	assert.Equal(t, `raw\n`, doc["lit"])
+	assert.Equal(t, []interface{}{[]interface{}{int64(1), int64(2)}, []interface{}{"x"}}, doc["arr"])
+	assert.Equal(t, map[string]interface{}{"x": int64(1), "y": map[string]interface{}{"z": "w"}}, doc["inline"])
+	assert.Equal(t, int64(8080), doc["server"].(map[string]interface{})["http"].(map[string]interface{})["port"])
+
+	fruits := doc["fruits"].([]map[string]interface{})
+	assert.Len(t, fruits, 2)
+	assert.Equal(t, "red", fruits[0]["color"].(map[string]interface{})["value"])
+	assert.Equal(t, "banana", fruits[1]["name"])
+}
+
+func TestParseTOMLError(t *testing.T) {
+	for _, s := range []string{
+		"a = ",
+		"a = 1\na = 2",
+		"a = 1 b = 2",
+		`a = "x`,
+		`a = "\q"`,
+		"a = [1, 2",
+		"a = 1__0",
+		"= 1",
+		"[a\nb = 1",
+		"a = 1\n[a]",
+		"a = {x = 1",
+	} {
+		_, err := parseTOML(s)
+		assert.Error(t, err, s)
+	}
+
+	_, err := parseTOML("a = 1\n\nb = ?")
+	assert.EqualError(t, err, `toml: line 3: invalid value "?"`)
+}
+
+func TestParseTOMLDepth(t *testing.T) {
+	_, err := parseTOML("a = " + strings.Repeat("[", MaxTOMLDepth) + strings.Repeat("]", MaxTOMLDepth))
+	assert.NoError(t, err)
+
+	_, err = parseTOML("a.b = " + strings.Repeat("[", MaxTOMLDepth+1))
+	assert.ErrorIs(t, err, ErrTooDeep)
+	assert.Equal(t, "a.b", err.(*DecodeError).Path)
+
+	_, err = parseTOML("x = " + strings.Repeat("{a = ", MaxTOMLDepth+1))
+	assert.ErrorIs(t, err, ErrTooDeep)
+
+	_, err = parseTOML(strings.Repeat("a.", MaxTOMLDepth) + "a = 1")
+	assert.ErrorIs(t, err, ErrTooDeep)
+}
+
+func TestBindingTOML(t *testing.T) {
+	testBodyBinding(t,
+		TOML, "toml",
+		"/", "/",
+		`foo = "bar"`, `bar = "foo"`)
+
+	var obj struct {
+		Name  string  `toml:"name"`
+		Ports []int   `toml:"ports"`
+		Ratio float64 `toml:"ratio"`
+		Owner struct {
+			Name string `toml:"name"`
+		} `toml:"owner"`
+		Items []struct {
+			ID int `toml:"id"`
+		} `toml:"items"`
+	}
+	req := requestWithBody("POST", "/", `
+name = "svc" # comment
+ports = [ 80,
+  443, ]
+ratio = 0.5
+[owner]
+name = 'tom'
+[[items]]
+id = 1
+[[items]]
+id = 2
+`)
+	assert.NoError(t, TOML.Bind(req, &obj))
+	assert.Equal(t, "svc", obj.Name)
+	assert.Equal(t, []int{80, 443}, obj.Ports)
+	assert.Equal(t, 0.5, obj.Ratio)
+	assert.Equal(t, "tom", obj.Owner.Name)
+	assert.Len(t, obj.Items, 2)
+	assert.Equal(t, 2, obj.Items[1].ID)
+
+	assert.Equal(t, TOML, NewBindinger("POST", MIMETOML+"; charset=utf-8"))
+
+	req = requestWithBody("POST", "/", "ratio = inf")
+	assert.EqualError(t, TOML.Bind(req, &obj), "toml: line 1: inf is not supported")
+}
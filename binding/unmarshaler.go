@@ -0,0 +1,56 @@
+package binding
+
+import (
+	"encoding"
+	"reflect"
+)
+
+// BindUnmarshaler is implemented by the types which bind themselves from a
+// value of form, query, header or uri, e.g. an enum:
+//
+//	func (s *Status) UnmarshalParam(param string) error {
+//		v, ok := statusByName[param]
+//		if !ok {
+//			return fmt.Errorf("unknown status %q", param)
+//		}
+//		*s = v
+//		return nil
+//	}
+//
+// It takes precedence over encoding.TextUnmarshaler.
+type BindUnmarshaler interface {
+	UnmarshalParam(param string) error
+}
+
+var (
+	bindUnmarshalerType = reflect.TypeOf((*BindUnmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// isUnmarshaler reports whether *typ is a BindUnmarshaler or encoding.TextUnmarshaler.
+// time.Time is excluded, it is set by the time_format tag.
+func isUnmarshaler(typ reflect.Type) bool {
+	if typ == timeType {
+		return false
+	}
+
+	pt := reflect.PtrTo(typ)
+	return pt.Implements(bindUnmarshalerType) || pt.Implements(textUnmarshalerType)
+}
+
+// trySetUnmarshaler sets value by its BindUnmarshaler or encoding.TextUnmarshaler,
+// ok is false if value is neither.
+func trySetUnmarshaler(value reflect.Value, val string) (ok bool, err error) {
+	if !value.CanAddr() || !isUnmarshaler(value.Type()) {
+		return false, nil
+	}
+
+	switch u := value.Addr().Interface().(type) {
+	case BindUnmarshaler:
+		return true, u.UnmarshalParam(val)
+	case encoding.TextUnmarshaler:
+		return true, u.UnmarshalText([]byte(val))
+	}
+
+	return false, nil
+}
@@ -0,0 +1,147 @@
+package binding
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type level int
+
+func (l *level) UnmarshalParam(param string) error {
+	switch param {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return fmt.Errorf("unknown level %q", param)
+	}
+	return nil
+}
+
+// UnmarshalText is shadowed by UnmarshalParam.
+func (l *level) UnmarshalText([]byte) error {
+	*l = -1
+	return nil
+}
+
+type unmarshalerForm struct {
+	Addr   netip.Addr   `form:"addr" query:"addr" header:"X-Addr" uri:"addr"`
+	PAddr  *netip.Addr  `form:"paddr" query:"paddr" header:"X-Paddr" uri:"paddr"`
+	IP     net.IP       `form:"ip" query:"ip" header:"X-Ip" uri:"ip"`
+	Addrs  []netip.Addr `form:"addrs" query:"addrs" header:"X-Addrs" uri:"addrs"`
+	Level  level        `form:"level" query:"level" header:"X-Level" uri:"level"`
+	Levels []*level     `form:"levels" query:"levels" header:"X-Levels" uri:"levels"`
+	Absent *netip.Addr  `form:"absent" query:"absent" header:"X-Absent" uri:"absent"`
+}
+
+func TestBindUnmarshaler(t *testing.T) {
+	values := url.Values{
+		"addr":   {"10.0.0.1"},
+		"paddr":  {"::1"},
+		"ip":     {"192.168.1.1"},
+		"addrs":  {"1.1.1.1", "8.8.8.8"},
+		"level":  {"high"},
+		"levels": {"low", "high"},
+	}
+	header := http.Header{}
+	for k, vs := range values {
+		for _, v := range vs {
+			header.Add("X-"+k, v)
+		}
+	}
+
+	check := func(name string, obj unmarshalerForm) {
+		assert.Equal(t, netip.MustParseAddr("10.0.0.1"), obj.Addr, name)
+		if assert.NotNil(t, obj.PAddr, name) {
+			assert.Equal(t, netip.MustParseAddr("::1"), *obj.PAddr, name)
+		}
+		assert.Equal(t, "192.168.1.1", obj.IP.String(), name)
+		assert.Equal(t, []netip.Addr{netip.MustParseAddr("1.1.1.1"), netip.MustParseAddr("8.8.8.8")}, obj.Addrs, name)
+		assert.Equal(t, level(2), obj.Level, name)
+		if assert.Len(t, obj.Levels, 2, name) {
+			assert.Equal(t, level(1), *obj.Levels[0], name)
+			assert.Equal(t, level(2), *obj.Levels[1], name)
+		}
+		assert.Nil(t, obj.Absent, name)
+	}
+
+	binds := map[string]func(*unmarshalerForm) error{
+		"form": func(obj *unmarshalerForm) error {
+			req := requestWithBody("POST", "/", values.Encode())
+			req.Header.Set("Content-Type", MIMEPOSTForm)
+			return Form.Bind(req, obj)
+		},
+		"form2": func(obj *unmarshalerForm) error {
+			req := requestWithBody("POST", "/", values.Encode())
+			req.Header.Set("Content-Type", MIMEPOSTForm)
+			return Form.Bind2(req, obj)
+		},
+		"query": func(obj *unmarshalerForm) error {
+			return Query.Bind(requestWithBody("GET", "/?"+values.Encode(), ""), obj)
+		},
+		"query2": func(obj *unmarshalerForm) error {
+			return Query.Bind2(requestWithBody("GET", "/?"+values.Encode(), ""), obj)
+		},
+		"header": func(obj *unmarshalerForm) error {
+			req := requestWithBody("GET", "/", "")
+			req.Header = header
+			return Header.Bind(req, obj)
+		},
+		"header2": func(obj *unmarshalerForm) error {
+			req := requestWithBody("GET", "/", "")
+			req.Header = header
+			return Header.Bind2(req, obj)
+		},
+		"uri": func(obj *unmarshalerForm) error {
+			return Uri.Bind(values, obj)
+		},
+		"uri2": func(obj *unmarshalerForm) error {
+			return Uri.Bind2(values, obj)
+		},
+	}
+	for name, bind := range binds {
+		var obj unmarshalerForm
+		if assert.NoError(t, bind(&obj), name) {
+			check(name, obj)
+		}
+	}
+
+	for _, bad := range []url.Values{{"addr": {"10.0.0"}}, {"levels": {"low", "middle"}}} {
+		var obj unmarshalerForm
+		err := Query.Bind(requestWithBody("GET", "/?"+bad.Encode(), ""), &obj)
+		assert.Error(t, err)
+
+		err = Query.Bind2(requestWithBody("GET", "/?"+bad.Encode(), ""), &obj)
+		assert.Error(t, err)
+	}
+
+	var obj unmarshalerForm
+	err := Query.Bind(requestWithBody("GET", "/?level=middle", ""), &obj)
+	assert.EqualError(t, err, `can't set level with middle: unknown level "middle"`)
+}
+
+func TestBindUnmarshalerNested(t *testing.T) {
+	var obj struct {
+		Hosts []struct {
+			Addr netip.Addr `form:"addr"`
+		} `form:"hosts"`
+		Levels map[string]level `form:"levels"`
+	}
+
+	form := map[string][]string{
+		"hosts[0][addr]": {"10.0.0.1"},
+		"hosts[1].addr":  {"10.0.0.2"},
+		"levels[a]":      {"low"},
+	}
+	assert.NoError(t, MapForm(&obj, form, nil, "form"))
+	assert.Len(t, obj.Hosts, 2)
+	assert.Equal(t, netip.MustParseAddr("10.0.0.2"), obj.Hosts[1].Addr)
+	assert.Equal(t, map[string]level{"a": 1}, obj.Levels)
+}
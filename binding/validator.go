@@ -1,6 +1,7 @@
 package binding
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
@@ -8,6 +9,7 @@ import (
 	"github.com/go-playground/validator/v10"
 )
 
+// sliceValidateError is the errors of elements by index, nil means valid.
 type sliceValidateError []error
 
 func (err sliceValidateError) Error() string {
@@ -43,14 +45,88 @@ type validatorV10 struct {
 }
 
 func NewvVlidatorV10() *validatorV10 {
+	return NewValidator("binding")
+}
+
+// NewValidator returns a validator using tagName, e.g. a validator per Engine
+// with its own rules.
+func NewValidator(tagName string) *validatorV10 {
 	v := &validatorV10{}
 
 	v.validate = validator.New()
-	v.validate.SetTagName("binding")
+	v.validate.SetTagName(tagName)
 
 	return v
 }
 
+// RuleFunc validates a field, see validator.FieldLevel.
+type RuleFunc = validator.Func
+
+// StructRuleFunc validates a struct, and reports the invalid fields by
+// validator.StructLevel.ReportError.
+type StructRuleFunc = validator.StructLevelFunc
+
+// RuleRegisterer is implemented by the StructValidater which supports custom rules.
+type RuleRegisterer interface {
+	RegisterRule(name string, fn RuleFunc) error
+	RegisterStructRule(typ interface{}, fn StructRuleFunc)
+	RegisterAlias(alias, tags string)
+	SetTagName(name string)
+}
+
+var _ RuleRegisterer = &validatorV10{}
+
+// ErrValidatorNotExtensible is returned when Validator is not a RuleRegisterer.
+var ErrValidatorNotExtensible = errors.New("binding: Validator does not support custom rules")
+
+// RegisterRule adds the rule of name to Validator.
+//
+//	binding.RegisterRule("even", func(fl validator.FieldLevel) bool {
+//		return fl.Field().Int()%2 == 0
+//	})
+func RegisterRule(name string, fn RuleFunc) error {
+	r, ok := Validator.(RuleRegisterer)
+	if !ok {
+		return ErrValidatorNotExtensible
+	}
+
+	return r.RegisterRule(name, fn)
+}
+
+// RegisterStructRule adds the rule of struct typ to Validator, typ is a value of the struct.
+func RegisterStructRule(typ interface{}, fn StructRuleFunc) error {
+	r, ok := Validator.(RuleRegisterer)
+	if !ok {
+		return ErrValidatorNotExtensible
+	}
+
+	r.RegisterStructRule(typ, fn)
+	return nil
+}
+
+// RegisterAlias adds alias of tags to Validator, e.g. RegisterAlias("color", "oneof=red green").
+func RegisterAlias(alias, tags string) error {
+	r, ok := Validator.(RuleRegisterer)
+	if !ok {
+		return ErrValidatorNotExtensible
+	}
+
+	r.RegisterAlias(alias, tags)
+	return nil
+}
+
+// SetTagName changes the tag of rules used by Validator, default is "binding".
+// It should be called before any validation.
+func SetTagName(name string) error {
+	r, ok := Validator.(RuleRegisterer)
+	if !ok {
+		return ErrValidatorNotExtensible
+	}
+
+	r.SetTagName(name)
+	return nil
+}
+
 func (v *validatorV10) Name() string {
 	return `validator.v10`
 }
@@ -59,6 +135,22 @@ func (v *validatorV10) Engine() interface{} {
 	return v.validate
 }
 
+func (v *validatorV10) RegisterRule(name string, fn RuleFunc) error {
+	return v.validate.RegisterValidation(name, fn)
+}
+
+func (v *validatorV10) RegisterStructRule(typ interface{}, fn StructRuleFunc) {
+	v.validate.RegisterStructValidation(fn, typ)
+}
+
+func (v *validatorV10) RegisterAlias(alias, tags string) {
+	v.validate.RegisterAlias(alias, tags)
+}
+
+func (v *validatorV10) SetTagName(name string) {
+	v.validate.SetTagName(name)
+}
+
 // ValidateStruct receives any kind of type, but only performed struct or pointer to struct type.
 func (v *validatorV10) ValidateStruct(obj interface{}) error {
 	if obj == nil {
@@ -73,14 +165,16 @@ func (v *validatorV10) ValidateStruct(obj interface{}) error {
 		return v.validate.Struct(obj)
 	case reflect.Slice, reflect.Array:
 		count := value.Len()
-		validateRet := make(sliceValidateError, 0)
+		validateRet := make(sliceValidateError, count) // keep the index of element
+		failed := false
 
 		for i := 0; i < count; i++ {
 			if err := v.ValidateStruct(value.Index(i).Interface()); err != nil {
-				validateRet = append(validateRet, err)
+				validateRet[i] = err
+				failed = true
 			}
 		}
-		if len(validateRet) == 0 {
+		if !failed {
 			return nil
 		}
 
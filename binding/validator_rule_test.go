@@ -0,0 +1,72 @@
+package binding
+
+import (
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatorRules(t *testing.T) {
+	type pair struct {
+		N     int    `rule:"even"`
+		Color string `rule:"color"`
+		Min   int
+		Max   int
+	}
+
+	v := NewValidator("rule")
+	assert.NoError(t, v.RegisterRule("even", func(fl validator.FieldLevel) bool {
+		return fl.Field().Int()%2 == 0
+	}))
+	v.RegisterAlias("color", "oneof=red green")
+	v.RegisterStructRule(pair{}, func(sl validator.StructLevel) {
+		if p := sl.Current().Interface().(pair); p.Min > p.Max {
+			sl.ReportError(p.Min, "Min", "Min", "ltefield", "Max")
+		}
+	})
+
+	assert.NoError(t, v.ValidateStruct(&pair{N: 2, Color: "red", Min: 1, Max: 2}))
+
+	err := v.ValidateStruct(&pair{N: 1, Color: "blue", Min: 3, Max: 2})
+	es := NewFieldErrors(&pair{}, err, nil)
+	assert.Len(t, es, 3)
+	assert.Equal(t, "even", es[0].Rule)
+	assert.Equal(t, "color", es[1].Rule)
+	assert.Equal(t, "ltefield", es[2].Rule)
+
+	// the default Validator is not changed
+	assert.NoError(t, Validate(&pair{N: 1}))
+}
+
+func TestRegisterRule(t *testing.T) {
+	defer func(v StructValidater) { Validator = v }(Validator)
+	Validator = NewvVlidatorV10()
+
+	assert.NoError(t, RegisterRule("even", func(fl validator.FieldLevel) bool {
+		return fl.Field().Int()%2 == 0
+	}))
+	assert.NoError(t, RegisterAlias("small", "max=10"))
+
+	var obj struct {
+		N int `binding:"even"`
+		M int `binding:"small"`
+	}
+	obj.N, obj.M = 1, 11
+	err := Validate(&obj)
+	assert.Len(t, err.(validator.ValidationErrors), 2)
+
+	assert.NoError(t, SetTagName("v"))
+	var obj2 struct {
+		N int `binding:"even" v:"min=2"`
+	}
+	obj2.N = 1
+	err = Validate(&obj2)
+	assert.Equal(t, "min", err.(validator.ValidationErrors)[0].Tag())
+
+	Validator = nil
+	assert.Equal(t, ErrValidatorNotExtensible, RegisterRule("x", nil))
+	assert.Equal(t, ErrValidatorNotExtensible, RegisterStructRule(obj, nil))
+	assert.Equal(t, ErrValidatorNotExtensible, RegisterAlias("x", "y"))
+	assert.Equal(t, ErrValidatorNotExtensible, SetTagName("x"))
+}
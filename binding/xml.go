@@ -1,11 +1,30 @@
 package binding
 
 import (
+	"bytes"
 	"encoding/xml"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
 )
 
+// XMLConfig is the config of decoding XML body, the zero value is the
+// default behavior of XML binding.
+// It is also a Bindinger for a call, e.g.
+//
+//	ctx.BindWith(&obj, binding.XMLConfig{MaxDepth: 16})
+type XMLConfig struct {
+	// MaxDepth is the max nesting of elements, 0 means no limit.
+	MaxDepth int
+	// MaxEntities is the max entity and character references in text and
+	// attributes, e.g. &amp; and &#38;, 0 means no limit.
+	MaxEntities int
+}
+
+// DefaultXMLConfig is used by XML binding.
+var DefaultXMLConfig XMLConfig
+
 type xmlBinding struct{}
 
 func (xmlBinding) Name() string {
@@ -13,15 +32,88 @@ func (xmlBinding) Name() string {
 }
 
 func (b xmlBinding) Bind(req *http.Request, obj interface{}) error {
+	if err := b.Decode(req, obj); err != nil {
+		return err
+	}
+
+	return validate(obj)
+}
+
+func (b xmlBinding) Decode(req *http.Request, obj interface{}) error {
+	return DefaultXMLConfig.Decode(req, obj)
+}
+
+func (XMLConfig) Name() string {
+	return "xml"
+}
+
+func (c XMLConfig) Bind(req *http.Request, obj interface{}) error {
+	if err := c.Decode(req, obj); err != nil {
+		return err
+	}
+
+	return validate(obj)
+}
+
+// Decode returns *DecodeError if the body exceeds the limits of c.
+func (c XMLConfig) Decode(req *http.Request, obj interface{}) error {
 	if req == nil || req.Body == nil {
-		return fmt.Errorf("invalid request for bind %s", b.Name())
+		return fmt.Errorf("invalid request for bind %s", c.Name())
 	}
 	defer req.Body.Close()
 
-	decoder := xml.NewDecoder(req.Body)
-	if err := decoder.Decode(obj); err != nil {
+	body := &bodyReader{Reader: req.Body}
+	if c.MaxDepth <= 0 && c.MaxEntities <= 0 {
+		decoder := xml.NewDecoder(body)
+		return body.check(decoder.Decode(obj))
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return BodyError(err)
+	}
+	if err := c.check(data); err != nil {
 		return err
 	}
 
-	return validate(obj)
+	return xml.NewDecoder(bytes.NewReader(data)).Decode(obj)
+}
+
+// check walks the tokens to find the element exceeding the limits.
+// The syntax error is left to the decoder.
+func (c XMLConfig) check(data []byte) error {
+	d := xml.NewDecoder(bytes.NewReader(data))
+
+	var path []string
+	entities := 0
+	for {
+		start := d.InputOffset()
+		tok, err := d.Token()
+		if err != nil {
+			return nil
+		}
+		raw := data[start:d.InputOffset()]
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			path = append(path, t.Name.Local)
+			if c.MaxDepth > 0 && len(path) > c.MaxDepth {
+				return &DecodeError{Path: strings.Join(path, "."), Err: ErrTooDeep}
+			}
+			entities += bytes.Count(raw, []byte("&"))
+		case xml.EndElement:
+			path = path[:len(path)-1]
+			if len(path) == 0 {
+				return nil
+			}
+		case xml.CharData:
+			if !bytes.HasPrefix(raw, []byte("<![CDATA[")) {
+				entities += bytes.Count(raw, []byte("&"))
+			}
+		}
+
+		if c.MaxEntities > 0 && entities > c.MaxEntities {
+			return &DecodeError{Path: strings.Join(path, "."), Err: ErrTooManyEntities}
+		}
+	}
 }
@@ -0,0 +1,33 @@
+package binding
+
+import (
+	"fmt"
+	"net/http"
+
+	"gopkg.in/yaml.v3"
+)
+
+type yamlBinding struct{}
+
+func (yamlBinding) Name() string {
+	return "yaml"
+}
+
+func (b yamlBinding) Bind(req *http.Request, obj interface{}) error {
+	if err := b.Decode(req, obj); err != nil {
+		return err
+	}
+
+	return validate(obj)
+}
+
+func (b yamlBinding) Decode(req *http.Request, obj interface{}) error {
+	if req == nil || req.Body == nil {
+		return fmt.Errorf("invalid request for bind %s", b.Name())
+	}
+	defer req.Body.Close()
+
+	body := &bodyReader{Reader: req.Body}
+	decoder := yaml.NewDecoder(body)
+	return body.check(decoder.Decode(obj))
+}
@@ -0,0 +1,69 @@
+package water
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/meilihao/water/binding"
+)
+
+// DefaultBodyCacheLimit is the default max bytes cached by ctx.Body().
+const DefaultBodyCacheLimit int64 = 1 << 20 // 1MB
+
+// ErrBodyCacheLimit is returned by ctx.Body() when the request body exceeds
+// the cache limit, the body is still readable from ctx.Request.Body.
+var ErrBodyCacheLimit = errors.New("water: request body exceeds the cache limit")
+
+// Body reads the request body once and caches it. ctx.Request.Body is rewound
+// to the cached bytes on every call, so the binders and handlers can read it again.
+// It replies 413 and returns *binding.BodyTooLargeError if the body exceeds
+// the limit of WithMaxBodyBytes or BodyLimit.
+func (ctx *Context) Body() ([]byte, error) {
+	if ctx.bodyCached {
+		ctx.rewindBody()
+		return ctx.body, nil
+	}
+
+	orig := ctx.Request.Body
+	if orig == nil || orig == http.NoBody {
+		ctx.bodyCached = true
+		return nil, nil
+	}
+
+	limit := ctx.options.BodyCacheLimit
+	data, err := io.ReadAll(io.LimitReader(orig, limit+1))
+	if err != nil {
+		return nil, ctx.abortOnBodyError(binding.BodyError(err))
+	}
+	if int64(len(data)) > limit {
+		ctx.Request.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(data), orig), orig}
+
+		return nil, ErrBodyCacheLimit
+	}
+	orig.Close()
+
+	ctx.body = data
+	ctx.bodyCached = true
+	ctx.rewindBody()
+
+	return data, nil
+}
+
+// BindBodyWith binds the cached body with b, so it can be called many times
+// with different Bindingers.
+func (ctx *Context) BindBodyWith(obj interface{}, b binding.Bindinger) error {
+	if _, err := ctx.Body(); err != nil {
+		return err
+	}
+
+	return ctx.BindWith(obj, b)
+}
+
+func (ctx *Context) rewindBody() {
+	ctx.Request.Body = io.NopCloser(bytes.NewReader(ctx.body))
+}
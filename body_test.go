@@ -0,0 +1,76 @@
+package water
+
+import (
+	"io"
+	"testing"
+
+	"github.com/meilihao/water/binding"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBody(t *testing.T) {
+	type foo struct {
+		Foo string `json:"foo" xml:"foo" form:"foo"`
+	}
+
+	Convey("Body is cached and re-readable", t, func() {
+		e := newTestEngine("POST", "/", func(ctx *Context) {
+			b1, err := ctx.Body()
+			So(err, ShouldBeNil)
+
+			b2, _ := io.ReadAll(ctx.Request.Body)
+			So(string(b2), ShouldEqual, string(b1))
+
+			b3, _ := ctx.BodyBytes()
+			So(string(b3), ShouldEqual, string(b1))
+
+			ctx.String(200, string(b1))
+		})
+
+		resp := serve(e, "POST", "/", `{"foo":"bar"}`, HeaderContentType, MIMEApplicationJSON)
+		So(resp.Body.String(), ShouldEqual, `{"foo":"bar"}`)
+	})
+
+	Convey("BindBodyWith", t, func() {
+		e := newTestEngine("POST", "/", func(ctx *Context) {
+			var a, b foo
+			So(ctx.BindBodyWith(&a, binding.JSON), ShouldBeNil)
+			So(ctx.BindBodyWith(&b, binding.JSON), ShouldBeNil)
+
+			var m map[string]interface{}
+			So(ctx.BindBodyWith(&m, binding.JSON), ShouldBeNil)
+
+			ctx.String(200, a.Foo+b.Foo+m["foo"].(string))
+		})
+
+		resp := serve(e, "POST", "/", `{"foo":"bar"}`, HeaderContentType, MIMEApplicationJSON)
+		So(resp.Body.String(), ShouldEqual, "barbarbar")
+	})
+
+	Convey("Bind after Logger", t, func() {
+		r := NewRouter()
+		r.Use(Logger())
+		r.POST("/", func(ctx *Context) {
+			var v foo
+			So(ctx.Bind(&v), ShouldBeNil)
+			ctx.String(200, v.Foo)
+		})
+		e := r.Handler()
+
+		resp := serve(e, "POST", "/", `{"foo":"bar"}`, HeaderContentType, MIMEApplicationJSON)
+		So(resp.Body.String(), ShouldEqual, "bar")
+	})
+
+	Convey("Body exceeds the cache limit", t, func() {
+		e := newTestEngine("POST", "/", func(ctx *Context) {
+			_, err := ctx.Body()
+			So(err, ShouldEqual, ErrBodyCacheLimit)
+
+			data, _ := io.ReadAll(ctx.Request.Body)
+			ctx.String(200, string(data))
+		}, WithBodyCacheLimit(4))
+
+		resp := serve(e, "POST", "/", `{"foo":"bar"}`, HeaderContentType, MIMEApplicationJSON)
+		So(resp.Body.String(), ShouldEqual, `{"foo":"bar"}`)
+	})
+}
@@ -15,6 +15,12 @@ const (
 	MIMETextHTMLCharsetUTF8        = MIMETextHTML + MIMESeparator + CharsetUTF8
 	MIMETextPlain                  = "text/plain"
 	MIMETextPlainCharsetUTF8       = MIMETextPlain + MIMESeparator + CharsetUTF8
+	MIMEApplicationJavaScript      = "application/javascript"
+	MIMEApplicationJavaScriptUTF8  = MIMEApplicationJavaScript + MIMESeparator + CharsetUTF8
+	MIMEApplicationYAML            = "application/yaml"
+	MIMEApplicationYAMLCharsetUTF8 = MIMEApplicationYAML + MIMESeparator + CharsetUTF8
+	MIMEApplicationMsgPack         = "application/msgpack"
+	MIMETextEventStream            = "text/event-stream"
 	MIMEXMLHttpRequest             = "XMLHttpRequest"
 )
 
@@ -23,9 +29,14 @@ const (
 const (
 	HeaderCacheControl = "Cache-Control" // Requests, Responses
 	HeaderContentType  = "Content-Type"  // Requests, Responses
+	HeaderConnection   = "Connection"    // Requests, Responses
+	HeaderUpgrade      = "Upgrade"       // Requests, Responses
 
+	HeaderAccept         = "Accept"           // Requests
+	HeaderAcceptCharset  = "Accept-Charset"   // Requests
 	HeaderUserAgent      = "User-Agent"       // Requests
 	HeaderXRequestedWith = "X-Requested-With" // Requests
+	HeaderForwarded      = "Forwarded"        // Requests, RFC 7239
 
 	HeaderExpires            = "Expires"             // Responses
 	HeaderContentDisposition = "Content-Disposition" // Responses
@@ -33,5 +44,13 @@ const (
 	// Common Non-Standard Response Headers
 	HeaderXForwardedFor   = "X-Forwarded-For"   // Requests
 	HeaderXForwardedProto = "X-Forwarded-Proto" // Requests
+	HeaderXForwardedHost  = "X-Forwarded-Host"  // Requests
 	HeaderXRealIP         = "X-Real-IP"         // Requests
+
+	// WebSocket, RFC 6455
+	HeaderSecWebSocketKey        = "Sec-WebSocket-Key"        // Requests
+	HeaderSecWebSocketVersion    = "Sec-WebSocket-Version"    // Requests, Responses
+	HeaderSecWebSocketAccept     = "Sec-WebSocket-Accept"     // Responses
+	HeaderSecWebSocketProtocol   = "Sec-WebSocket-Protocol"   // Requests, Responses
+	HeaderSecWebSocketExtensions = "Sec-WebSocket-Extensions" // Requests, Responses
 )
@@ -1,8 +1,8 @@
 package water
 
 import (
-	"encoding/xml"
 	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"mime/multipart"
@@ -11,7 +11,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/meilihao/logx"
 	"github.com/meilihao/water/binding"
 )
 
@@ -26,6 +25,7 @@ func (ctx *Context) SetHeader(key, value string) {
 }
 
 // https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/X-Forwarded-Proto
+// Deprecated: it trusts X-Forwarded-Proto from any client, please use Scheme().
 func (ctx *Context) Protocol() string {
 	switch s := ctx.GetHeader(HeaderXForwardedProto); s {
 	case "http", "https":
@@ -109,6 +109,7 @@ func (ctx *Context) IsWebsocket() bool {
 
 // IP returns request client ip.
 // if using proxy, return first proxy ip.
+// Deprecated: it trusts X-Real-IP and X-Forwarded-For from any client, please use ClientIP().
 func (ctx *Context) RealIp() string {
 	return requestRealIp(ctx.Request)
 }
@@ -129,11 +130,17 @@ func (ctx *Context) BodyString() (string, error) {
 	return string(data), err
 }
 
-// BodyBytes returns content of request body in bytes.
+// BodyBytes returns content of request body in bytes, it returns the cached
+// body if ctx.Body() has been called.
+// It replies 413 and returns *binding.BodyTooLargeError if the body exceeds the limit.
 func (ctx *Context) BodyBytes() ([]byte, error) {
+	if ctx.bodyCached {
+		return ctx.Body()
+	}
+
 	data, err := ioutil.ReadAll(ctx.Request.Body)
 	if err != nil {
-		return nil, err
+		return nil, ctx.abortOnBodyError(binding.BodyError(err))
 	}
 	ctx.Request.Body.Close()
 	return data, err
@@ -247,61 +254,178 @@ func (ctx *Context) Stream(contentType string, r io.Reader) error {
 }
 
 func (ctx *Context) String(code int, str string) {
-	ctx.WriteHeader(code)
-	ctx.Header().Set(HeaderContentType, MIMETextPlainCharsetUTF8)
-	ctx.Write([]byte(str))
+	ctx.Render(code, stringRender{MIMETextPlainCharsetUTF8, str})
 }
 
+// Data writes data with contentType, "" means detecting by data.
 func (ctx *Context) Data(code int, contentType string, data []byte) {
-	ctx.WriteHeader(code)
-	ctx.Header().Set(HeaderContentType, contentType)
-	ctx.Write(data)
+	ctx.Render(code, dataRender{contentType, data})
 }
 
 // HTMLRaw not use render
 func (ctx *Context) HTMLRaw(code int, str string) {
-	ctx.WriteHeader(code)
-	ctx.Header().Set(HeaderContentType, MIMETextHTMLCharsetUTF8)
-	ctx.Write([]byte(str))
+	ctx.Render(code, stringRender{MIMETextHTMLCharsetUTF8, str})
 }
 
+// JSON renders v as JSON, HTML characters are escaped.
 func (ctx *Context) JSON(code int, v interface{}) error {
-	ctx.WriteHeader(code)
-	ctx.Header().Set(HeaderContentType, MIMEApplicationJSONCharsetUTF8)
-	err := json.NewEncoder(ctx).Encode(v)
-	if err != nil {
-		logx.Warn(err)
-	}
-	return err
+	return ctx.Render(code, jsonRender{v})
 }
 
 func (ctx *Context) XML(code int, v interface{}) error {
-	ctx.WriteHeader(code)
-	ctx.Header().Set(HeaderContentType, MIMEApplicationXMLCharsetUTF8)
-	err := xml.NewEncoder(ctx).Encode(v)
-	if err != nil {
-		logx.Warn(err)
-	}
-	return err
+	return ctx.Render(code, xmlRender{v})
 }
 
 // Bind use http method and ContentType to decode req
 func (ctx *Context) Bind(obj interface{}) error {
 	b := binding.NewBindinger(ctx.Request.Method, ctx.ContentType())
-	if b != binding.JSON && b != binding.XML {
-		ctx.ParseFormOrMultipartForm()
-	}
 
 	return ctx.BindWith(obj, b)
 }
 
-// BindWith use the assigned Bindinger to decode req
+// BindWith use the assigned Bindinger to decode req.
+// It replies 413 and returns *binding.BodyTooLargeError if the body exceeds the limit,
+// or 415 and returns *binding.UnsupportedMediaTypeError if no Bindinger is
+// registered for the content type, see binding.Register.
 func (ctx *Context) BindWith(obj interface{}, b binding.Bindinger) error {
+	if ctx.bodyCached {
+		ctx.rewindBody()
+	}
+
 	if b != binding.JSON && b != binding.XML {
-		ctx.ParseFormOrMultipartForm()
+		if err := ctx.parseForm(); err != nil {
+			return ctx.abortOnBodyError(err)
+		}
+	}
+
+	if d, ok := b.(binding.Decoder); ok {
+		return ctx.abortOnBodyError(ctx.decodeAndValidate(d, obj))
+	}
+
+	// b validates obj itself, the validator of engine still applies
+	if err := b.Bind(ctx.Request, obj); err != nil || ctx.options.Validator == nil {
+		return ctx.abortOnBodyError(err)
+	}
+
+	return ctx.validate(obj)
+}
+
+// decodeAndValidate is Bind which validates obj by ctx.validate.
+func (ctx *Context) decodeAndValidate(d binding.Decoder, obj interface{}) error {
+	if err := d.Decode(ctx.Request, obj); err != nil {
+		return err
+	}
+
+	return ctx.validate(obj)
+}
+
+// validate validates obj by the validator of engine, default is binding.Validator.
+func (ctx *Context) validate(obj interface{}) error {
+	if ctx.options.Validator != nil {
+		return ctx.options.Validator.ValidateStruct(obj)
+	}
+
+	return binding.Validate(obj)
+}
+
+// abortOnBodyError replies 413 if err is *binding.BodyTooLargeError, 415 if
+// err is *binding.UnsupportedMediaTypeError, and returns err.
+func (ctx *Context) abortOnBodyError(err error) error {
+	if ctx.Written() {
+		return err
+	}
+
+	var tl *binding.BodyTooLargeError
+	var um *binding.UnsupportedMediaTypeError
+	switch {
+	case errors.As(err, &tl):
+		ctx.Abort(tl.StatusCode())
+	case errors.As(err, &um):
+		ctx.Abort(um.StatusCode())
 	}
 
-	return b.Bind(ctx.Request, obj)
+	return err
+}
+
+// BindURI binds the uri params to obj by the "uri" tag.
+func (ctx *Context) BindURI(obj interface{}) error {
+	if err := binding.MapForm(obj, ctx.Params.toForm(), nil, "uri"); err != nil {
+		return err
+	}
+
+	return ctx.validate(obj)
+}
+
+// BindHeader binds the request headers to obj by the "header" tag.
+func (ctx *Context) BindHeader(obj interface{}) error {
+	return ctx.decodeAndValidate(binding.Header, obj)
+}
+
+// BindQuery binds the URL query to obj by the "form" tag, the body is not read.
+func (ctx *Context) BindQuery(obj interface{}) error {
+	return ctx.decodeAndValidate(binding.Query, obj)
+}
+
+// BindAll fills obj from query("form" tag), headers("header" tag), body and
+// uri params("uri" tag) in order, the later source overrides the former,
+// then validates obj once.
+// It replies 413 or 415 like BindWith.
+func (ctx *Context) BindAll(obj interface{}) error {
+	return ctx.abortOnBodyError(ctx.bindAll(obj))
+}
+
+// bindAll is BindAll without replying.
+// query, headers and uri params are only mapped to struct.
+func (ctx *Context) bindAll(obj interface{}) error {
+	isStruct := isStructPtr(obj)
+
+	if isStruct {
+		if err := binding.MapForm(obj, ctx.Request.URL.Query(), nil, "form"); err != nil {
+			return err
+		}
+		if err := binding.MapForm(obj, ctx.Request.Header, nil, "header"); err != nil {
+			return err
+		}
+	}
+
+	if ctx.hasBody() {
+		b := binding.NewBindinger(ctx.Request.Method, ctx.ContentType())
+		d, ok := b.(binding.Decoder)
+		if !ok {
+			return fmt.Errorf("binding %s is not a binding.Decoder", b.Name())
+		}
+
+		if ctx.bodyCached {
+			ctx.rewindBody()
+		}
+		if b != binding.JSON && b != binding.XML {
+			if err := ctx.parseForm(); err != nil {
+				return err
+			}
+		}
+
+		if err := d.Decode(ctx.Request, obj); err != nil && err != io.EOF {
+			return err
+		}
+	}
+
+	if isStruct && len(ctx.Params) > 0 {
+		if err := binding.MapForm(obj, ctx.Params.toForm(), nil, "uri"); err != nil {
+			return err
+		}
+	}
+
+	return ctx.validate(obj)
+}
+
+// hasBody reports whether the request may carry a body to bind.
+func (ctx *Context) hasBody() bool {
+	req := ctx.Request
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		return false
+	}
+
+	return req.Body != nil && req.Body != http.NoBody && req.ContentLength != 0
 }
 
 // HandlerName returns the last handler's name.
@@ -325,20 +449,45 @@ var (
 )
 
 // ParseFormOrMultipartForm parses the raw query from the URL.
+// It replies 413 if the body exceeds the limit, and panics for other errors.
 func (ctx *Context) ParseFormOrMultipartForm() {
-	if ctx.parsedParams {
+	err := ctx.parseForm()
+	if err == nil {
 		return
 	}
+	if _, ok := err.(*binding.BodyTooLargeError); ok {
+		ctx.abortOnBodyError(err)
+		return
+	}
+
+	panic(err)
+}
+
+// parseForm parses the form only once, and remembers the error.
+func (ctx *Context) parseForm() error {
+	if ctx.parsedParams {
+		return ctx.bodyErr
+	}
 	ctx.parsedParams = true
 
 	if (ctx.Request.Method == http.MethodPost || ctx.Request.Method == http.MethodPut || ctx.Request.Method == http.MethodPatch) &&
 		strings.Contains(ctx.Request.Header.Get("Content-Type"), "multipart/form-data") {
 		if err := ctx.Request.ParseMultipartForm(defaultMultipartMemory); err != nil {
-			panic(errors.New("parseMultipartForm error:" + err.Error()))
+			ctx.bodyErr = formError("parseMultipartForm", err)
 		}
 	} else {
 		if err := ctx.Request.ParseForm(); err != nil {
-			panic(errors.New("parseForm error:" + err.Error()))
+			ctx.bodyErr = formError("parseForm", err)
 		}
 	}
+
+	return ctx.bodyErr
+}
+
+func formError(op string, err error) error {
+	if err = binding.BodyError(err); errors.As(err, new(*binding.BodyTooLargeError)) {
+		return err
+	}
+
+	return errors.New(op + " error:" + err.Error())
 }
@@ -2,15 +2,14 @@ package water
 
 import (
 	"net/http"
-
-	"github.com/meilihao/logx"
+	"time"
 )
 
 // Context represents the context of current request of water instance.
 // Context体现了water处理当前请求时的上下文环境
-// 不在Context中使用"Resp  ResponseWriter"的原因:Resp.Write()和Ctx.Write()的行为不一致,
-// 因Resp.Write()不会设置ctx.written,导致在Recovery()等地方重复调用WriteHeader而报错
-// "http: multiple response.WriteHeader calls".
+// ResponseWriter是water包装的http.ResponseWriter,会记录status和写入的字节数,
+// 因此直接调用ctx.ResponseWriter.Write()或http.ServeFile(ctx.ResponseWriter, ...)后,
+// ctx.Written()依然正确,Recovery()等地方不会重复调用WriteHeader.
 type Context struct {
 	Environ Environ
 	Params  Params
@@ -23,25 +22,48 @@ type Context struct {
 	// --- follow need reset
 	index int
 
-	written bool
-	status  int
+	rw responseWriter
 
 	endNode      *node // matched route node
 	parsedParams bool
+	bodyErr      error // error of parseForm
+	body         []byte
+	bodyCached   bool
+	session      *Session
+	services     map[*service]interface{} // request-scoped services
+	fwd          forwardedHop             // see ClientIP()
+	fwdParsed    bool
+	options      *options // of the engine, set by Engine.ServeHTTP
 }
 
 func newContext() *Context {
-	return &Context{}
+	return &Context{
+		Environ: make(Environ),
+		options: &defaultOptions,
+	}
 }
 
-func (ctx *Context) reset() {
+func (ctx *Context) reset(rw http.ResponseWriter) {
 	ctx.index = 0
 
-	ctx.written = false
-	ctx.status = 0
+	ctx.rw.reset(rw)
+	ctx.ResponseWriter = &ctx.rw
 
 	ctx.endNode = nil
 	ctx.parsedParams = false
+	ctx.bodyErr = nil
+	ctx.body = nil
+	ctx.bodyCached = false
+	ctx.session = nil
+	ctx.fwdParsed = false
+
+	// Environ is reused by the next requests
+	for k := range ctx.Environ {
+		delete(ctx.Environ, k)
+	}
+	for k := range ctx.services {
+		delete(ctx.services, k)
+	}
 }
 
 func (ctx *Context) Next() {
@@ -54,37 +76,35 @@ func (ctx *Context) run() {
 		ctx.handlers[ctx.index].ServeHTTP(ctx)
 		ctx.index += 1
 
-		if ctx.written {
+		if ctx.rw.written {
 			return
 		}
 	}
 }
 
+// Written reports whether the status line has been written.
 func (ctx *Context) Written() bool {
-	return ctx.written
+	return ctx.rw.written
 }
 
+// Status returns the written status code, 0 if not written.
 func (ctx *Context) Status() int {
-	return ctx.status
+	return ctx.rw.status
 }
 
-func (ctx *Context) WriteHeader(code int) {
-	if ctx.written {
-		logx.Warn("water: multiple ctx.WriteHeader calls")
-	} else {
-		ctx.written = true
-		ctx.status = code
-		ctx.ResponseWriter.WriteHeader(code)
-	}
+// Size returns the bytes of the written response body.
+func (ctx *Context) Size() int {
+	return ctx.rw.size
 }
 
-func (ctx *Context) Write(data []byte) (int, error) {
-	if !ctx.written {
-		header := ctx.Header()
-		if len(data) > 0 && header.Get("Content-Type") == "" {
-			header.Set("Content-Type", http.DetectContentType(data))
-		}
-		ctx.WriteHeader(http.StatusOK)
-	}
-	return ctx.ResponseWriter.Write(data)
+// WrittenAt returns the time when the status line is written.
+func (ctx *Context) WrittenAt() time.Time {
+	return ctx.rw.writeAt
+}
+
+// OnBeforeWrite registers f to run just before the status line is written,
+// so that f can still modify the response headers, e.g. Set-Cookie.
+// The hooks run in the order of registration.
+func (ctx *Context) OnBeforeWrite(f func()) {
+	ctx.rw.beforeWrite = append(ctx.rw.beforeWrite, f)
 }
@@ -1,9 +1,13 @@
 package water
 
 import (
+	"errors"
+	"fmt"
 	"html/template"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 )
 
 func (ctx *Context) Cookie(name string) string {
@@ -53,6 +57,8 @@ func (ctx *Context) CookieFloat64(name string) float64 {
 // others... : MaxAge, Path, Domain, Secure, HttpOnly, SameSite.
 // The provided cookie must have a valid Name. Invalid cookies may be
 // silently dropped.
+// It is not affected by CookiePolicy, use SetCookieWith to apply the policy
+// and get the validation error.
 func (ctx *Context) SetCookie(name string, value string, others ...interface{}) {
 	cookie := http.Cookie{}
 	cookie.Name = name
@@ -107,3 +113,150 @@ func (ctx *Context) SetCookie(name string, value string, others ...interface{})
 
 	ctx.ResponseWriter.Header().Add("Set-Cookie", cookie.String())
 }
+
+// ErrInvalidCookie is wrapped by the errors of SetCookieWith and DeleteCookie.
+var ErrInvalidCookie = errors.New("water: invalid cookie")
+
+// CookiePolicy is the engine-wide default of the cookies set by SetCookieWith
+// and DeleteCookie. The flags are only turned on, not off.
+type CookiePolicy struct {
+	Path        string // default "/"
+	Domain      string
+	Secure      bool
+	HttpOnly    bool
+	SameSite    http.SameSite
+	Partitioned bool
+}
+
+// cookieSpec is http.Cookie with the attributes which http.Cookie lacks before go1.23.
+type cookieSpec struct {
+	*http.Cookie
+	partitioned bool
+}
+
+// CookieOption sets an attribute of the cookie for SetCookieWith and DeleteCookie.
+type CookieOption func(*cookieSpec)
+
+func CookieMaxAge(seconds int) CookieOption {
+	return func(c *cookieSpec) {
+		c.MaxAge = seconds
+	}
+}
+
+func CookieExpires(t time.Time) CookieOption {
+	return func(c *cookieSpec) {
+		c.Expires = t
+	}
+}
+
+func CookiePath(path string) CookieOption {
+	return func(c *cookieSpec) {
+		c.Path = path
+	}
+}
+
+func CookieDomain(domain string) CookieOption {
+	return func(c *cookieSpec) {
+		c.Domain = domain
+	}
+}
+
+func CookieSecure() CookieOption {
+	return func(c *cookieSpec) {
+		c.Secure = true
+	}
+}
+
+func CookieHttpOnly() CookieOption {
+	return func(c *cookieSpec) {
+		c.HttpOnly = true
+	}
+}
+
+func CookieSameSite(mode http.SameSite) CookieOption {
+	return func(c *cookieSpec) {
+		c.SameSite = mode
+	}
+}
+
+// CookiePartitioned sets the Partitioned attribute (CHIPS), it needs Secure.
+func CookiePartitioned() CookieOption {
+	return func(c *cookieSpec) {
+		c.partitioned = true
+	}
+}
+
+// SetCookieWith validates the cookie and adds it to the response, c is not modified.
+// The unset attributes are taken from CookiePolicy, and opts are applied at last.
+// The prefix rules are enforced: "__Secure-" needs Secure, and "__Host-" also
+// needs Path "/" and no Domain.
+func (ctx *Context) SetCookieWith(c *http.Cookie, opts ...CookieOption) error {
+	policy := &ctx.options.CookiePolicy
+
+	cc := *c
+	spec := cookieSpec{Cookie: &cc}
+
+	if cc.Path == "" {
+		cc.Path = policy.Path
+	}
+	if cc.Domain == "" {
+		cc.Domain = policy.Domain
+	}
+	if cc.SameSite == 0 {
+		cc.SameSite = policy.SameSite
+	}
+	cc.Secure = cc.Secure || policy.Secure
+	cc.HttpOnly = cc.HttpOnly || policy.HttpOnly
+	spec.partitioned = policy.Partitioned
+
+	for _, opt := range opts {
+		opt(&spec)
+	}
+
+	if err := spec.valid(); err != nil {
+		return err
+	}
+
+	v := cc.String()
+	if spec.partitioned {
+		v += "; Partitioned"
+	}
+	ctx.ResponseWriter.Header().Add("Set-Cookie", v)
+
+	return nil
+}
+
+// DeleteCookie expires the cookie of name, the Path and Domain must match the set one.
+func (ctx *Context) DeleteCookie(name string, opts ...CookieOption) error {
+	return ctx.SetCookieWith(&http.Cookie{
+		Name:    name,
+		MaxAge:  -1,
+		Expires: time.Unix(0, 0),
+	}, opts...)
+}
+
+func (c *cookieSpec) valid() error {
+	if err := c.Cookie.Valid(); err != nil {
+		return fmt.Errorf("%w %q: %v", ErrInvalidCookie, c.Name, err)
+	}
+
+	switch {
+	case strings.HasPrefix(c.Name, "__Host-"):
+		if !c.Secure || c.Path != "/" || c.Domain != "" {
+			return fmt.Errorf("%w %q: __Host- prefix needs Secure, Path \"/\" and no Domain", ErrInvalidCookie, c.Name)
+		}
+	case strings.HasPrefix(c.Name, "__Secure-"):
+		if !c.Secure {
+			return fmt.Errorf("%w %q: __Secure- prefix needs Secure", ErrInvalidCookie, c.Name)
+		}
+	}
+
+	if c.partitioned && !c.Secure {
+		return fmt.Errorf("%w %q: Partitioned needs Secure", ErrInvalidCookie, c.Name)
+	}
+	if c.SameSite == http.SameSiteNoneMode && !c.Secure {
+		return fmt.Errorf("%w %q: SameSite=None needs Secure", ErrInvalidCookie, c.Name)
+	}
+
+	return nil
+}
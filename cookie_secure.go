@@ -0,0 +1,171 @@
+package water
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"time"
+)
+
+var errCookieKeys = errors.New("water: no cookie keys")
+
+// CookieKeys is an ordered key ring for the signed and encrypted cookies.
+// The first key signs or encrypts, and all keys verify or decrypt, so a new
+// key can be put in front while the old ones still work during rotation.
+type CookieKeys [][]byte
+
+// SetSignedCookie sets a cookie signed by HMAC-SHA256, others are the same as SetCookie.
+// The expiry from MaxAge is embedded in the payload, so an expired value
+// is rejected even if the client keeps it.
+func (ctx *Context) SetSignedCookie(keys CookieKeys, name, value string, others ...interface{}) {
+	if len(keys) == 0 {
+		panic(errCookieKeys)
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(cookiePayload(value, others))
+	ctx.SetCookie(name, payload+"."+base64.RawURLEncoding.EncodeToString(keys.sign(name+"="+payload)), others...)
+}
+
+// SignedCookie returns the value of cookie set by SetSignedCookie,
+// false if not exist, tampered or expired.
+func (ctx *Context) SignedCookie(keys CookieKeys, name string) (string, bool) {
+	v := ctx.Cookie(name)
+
+	i := strings.LastIndexByte(v, '.')
+	if i < 0 {
+		return "", false
+	}
+	payload := v[:i]
+
+	mac, err := base64.RawURLEncoding.DecodeString(v[i+1:])
+	if err != nil || !keys.verify(name+"="+payload, mac) {
+		return "", false
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", false
+	}
+
+	return parseCookiePayload(data)
+}
+
+// SetEncryptedCookie sets a cookie encrypted by AES-GCM, others are the same as SetCookie.
+// The keys can be any length, AES-256 keys are derived from them.
+func (ctx *Context) SetEncryptedCookie(keys CookieKeys, name, value string, others ...interface{}) error {
+	if len(keys) == 0 {
+		panic(errCookieKeys)
+	}
+
+	aead, err := cookieAEAD(keys[0])
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+8+len(value)+aead.Overhead())
+	if _, err = rand.Read(nonce); err != nil {
+		return err
+	}
+
+	data := aead.Seal(nonce, nonce, cookiePayload(value, others), []byte(name))
+	ctx.SetCookie(name, base64.RawURLEncoding.EncodeToString(data), others...)
+
+	return nil
+}
+
+// EncryptedCookie returns the value of cookie set by SetEncryptedCookie,
+// false if not exist, tampered or expired.
+func (ctx *Context) EncryptedCookie(keys CookieKeys, name string) (string, bool) {
+	data, err := base64.RawURLEncoding.DecodeString(ctx.Cookie(name))
+	if err != nil || len(data) == 0 {
+		return "", false
+	}
+
+	for _, k := range keys {
+		aead, err := cookieAEAD(k)
+		if err != nil || len(data) < aead.NonceSize() {
+			continue
+		}
+
+		plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], []byte(name))
+		if err == nil {
+			return parseCookiePayload(plain)
+		}
+	}
+
+	return "", false
+}
+
+func (ks CookieKeys) sign(payload string) []byte {
+	return sign(ks[0], payload)
+}
+
+func (ks CookieKeys) verify(payload string, mac []byte) bool {
+	for _, k := range ks {
+		if hmac.Equal(mac, sign(k, payload)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sign(key []byte, payload string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
+
+// cookieAEAD derives an AES-256 key from key, so the signing and encryption
+// never use the same key.
+func cookieAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(sign(key, "water-cookie-encryption"))
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// cookiePayload is the expiry of unix seconds in 8 bytes followed by value,
+// expiry is 0 for the cookie without MaxAge.
+func cookiePayload(value string, others []interface{}) []byte {
+	var expires int64
+	if len(others) > 0 {
+		var maxAge int64
+		switch v := others[0].(type) {
+		case int:
+			maxAge = int64(v)
+		case int32:
+			maxAge = int64(v)
+		case int64:
+			maxAge = v
+		}
+		if maxAge != 0 {
+			expires = time.Now().Unix() + maxAge
+		}
+	}
+
+	b := make([]byte, 8, 8+len(value))
+	binary.BigEndian.PutUint64(b, uint64(expires))
+	return append(b, value...)
+}
+
+func parseCookiePayload(data []byte) (string, bool) {
+	if len(data) < 8 {
+		return "", false
+	}
+
+	expires := int64(binary.BigEndian.Uint64(data))
+	if expires != 0 && time.Now().Unix() >= expires {
+		return "", false
+	}
+
+	return string(data[8:]), true
+}
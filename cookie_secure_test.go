@@ -0,0 +1,82 @@
+package water
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func serveCookie(h func(ctx *Context), cookies ...*http.Cookie) []*http.Cookie {
+	req := newTestRequest("GET", "/", "")
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+
+	return serveRequest(newTestEngine("GET", "/", h), req).Result().Cookies()
+}
+
+func TestSecureCookie(t *testing.T) {
+	oldKeys := CookieKeys{[]byte("old")}
+	keys := CookieKeys{[]byte("new"), []byte("old")}
+
+	Convey("SignedCookie", t, func() {
+		cs := serveCookie(func(ctx *Context) {
+			ctx.SetSignedCookie(oldKeys, "uid", "100", 60)
+		})
+		So(cs, ShouldHaveLength, 1)
+		So(cs[0].MaxAge, ShouldEqual, 60)
+
+		serveCookie(func(ctx *Context) {
+			v, ok := ctx.SignedCookie(keys, "uid")
+			So(ok, ShouldBeTrue)
+			So(v, ShouldEqual, "100")
+
+			_, ok = ctx.SignedCookie(CookieKeys{[]byte("other")}, "uid")
+			So(ok, ShouldBeFalse)
+		}, cs[0])
+
+		// the value can't be moved to another cookie
+		serveCookie(func(ctx *Context) {
+			_, ok := ctx.SignedCookie(keys, "admin")
+			So(ok, ShouldBeFalse)
+		}, &http.Cookie{Name: "admin", Value: cs[0].Value})
+
+		serveCookie(func(ctx *Context) {
+			_, ok := ctx.SignedCookie(keys, "uid")
+			So(ok, ShouldBeFalse)
+		}, &http.Cookie{Name: "uid", Value: "x" + cs[0].Value})
+	})
+
+	Convey("EncryptedCookie", t, func() {
+		cs := serveCookie(func(ctx *Context) {
+			So(ctx.SetEncryptedCookie(oldKeys, "token", "secret"), ShouldBeNil)
+		})
+		So(cs, ShouldHaveLength, 1)
+		So(cs[0].Value, ShouldNotContainSubstring, "secret")
+
+		serveCookie(func(ctx *Context) {
+			v, ok := ctx.EncryptedCookie(keys, "token")
+			So(ok, ShouldBeTrue)
+			So(v, ShouldEqual, "secret")
+
+			_, ok = ctx.EncryptedCookie(CookieKeys{[]byte("other")}, "token")
+			So(ok, ShouldBeFalse)
+		}, cs[0])
+	})
+
+	Convey("Expired", t, func() {
+		cs := serveCookie(func(ctx *Context) {
+			ctx.SetSignedCookie(keys, "a", "1", -1)
+			ctx.SetEncryptedCookie(keys, "b", "1", -1)
+		})
+
+		// the browser drops them, but the client may keep sending them
+		serveCookie(func(ctx *Context) {
+			_, ok := ctx.SignedCookie(keys, "a")
+			So(ok, ShouldBeFalse)
+			_, ok = ctx.EncryptedCookie(keys, "b")
+			So(ok, ShouldBeFalse)
+		}, &http.Cookie{Name: "a", Value: cs[0].Value}, &http.Cookie{Name: "b", Value: cs[1].Value})
+	})
+}
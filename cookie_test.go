@@ -0,0 +1,71 @@
+package water
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func serveSetCookie(h func(ctx *Context), opts ...Option) []string {
+	resp := serve(newTestEngine("GET", "/", h, opts...), "GET", "/", "")
+
+	return resp.Header().Values("Set-Cookie")
+}
+
+func TestSetCookieWith(t *testing.T) {
+	Convey("options", t, func() {
+		vs := serveSetCookie(func(ctx *Context) {
+			err := ctx.SetCookieWith(&http.Cookie{Name: "a", Value: "1"},
+				CookieMaxAge(60), CookieSecure(), CookieHttpOnly(),
+				CookieSameSite(http.SameSiteNoneMode), CookiePartitioned())
+			So(err, ShouldBeNil)
+		})
+		So(vs, ShouldResemble, []string{"a=1; Path=/; Max-Age=60; HttpOnly; Secure; SameSite=None; Partitioned"})
+	})
+
+	Convey("invalid", t, func() {
+		serveSetCookie(func(ctx *Context) {
+			for _, c := range []*http.Cookie{
+				{Name: "a b", Value: "1"},
+				{Name: "a", Value: "1;2"},
+				{Name: "__Secure-a", Value: "1"},
+				{Name: "__Host-a", Value: "1", Secure: true, Domain: "example.com"},
+				{Name: "__Host-a", Value: "1", Secure: true, Path: "/admin"},
+				{Name: "a", Value: "1", SameSite: http.SameSiteNoneMode},
+			} {
+				So(errors.Is(ctx.SetCookieWith(c), ErrInvalidCookie), ShouldBeTrue)
+			}
+
+			So(errors.Is(ctx.SetCookieWith(&http.Cookie{Name: "a", Value: "1"}, CookiePartitioned()), ErrInvalidCookie), ShouldBeTrue)
+			So(ctx.SetCookieWith(&http.Cookie{Name: "__Host-a", Value: "1", Secure: true}), ShouldBeNil)
+		})
+	})
+
+	Convey("SetCookie is not validated", t, func() {
+		vs := serveSetCookie(func(ctx *Context) {
+			ctx.SetCookie("a", "1", 60, "/", "", true, true)
+			ctx.SetCookie("b", "2", 0, "/", "", false, false, http.SameSiteNoneMode)
+		})
+		So(vs, ShouldResemble, []string{"a=1; Path=/; Max-Age=60; HttpOnly; Secure", "b=2; Path=/; SameSite=None"})
+	})
+
+	Convey("DeleteCookie", t, func() {
+		vs := serveSetCookie(func(ctx *Context) {
+			So(ctx.DeleteCookie("a"), ShouldBeNil)
+		})
+		So(vs, ShouldResemble, []string{"a=; Path=/; Expires=Thu, 01 Jan 1970 00:00:00 GMT; Max-Age=0"})
+	})
+
+	Convey("CookiePolicy", t, func() {
+		vs := serveSetCookie(func(ctx *Context) {
+			ctx.SetCookie("a", "1")
+			So(ctx.SetCookieWith(&http.Cookie{Name: "b", Value: "2", Path: "/b"}), ShouldBeNil)
+		}, WithCookiePolicy(CookiePolicy{Secure: true, HttpOnly: true, SameSite: http.SameSiteStrictMode}))
+		So(vs, ShouldResemble, []string{
+			"a=1; Path=/",
+			"b=2; Path=/b; HttpOnly; Secure; SameSite=Strict",
+		})
+	})
+}
@@ -1,6 +1,7 @@
 package water
 
 import (
+	"net"
 	"net/http"
 	"sync"
 	"time"
@@ -94,10 +95,14 @@ func (e *Engine) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	}
 
 	ctx := e.ctxPool.Get().(*Context)
-	ctx.reset()
+	ctx.reset(rw)
+
+	if e.options.MaxBodyBytes > 0 && req.Body != nil {
+		req.Body = http.MaxBytesReader(rw, req.Body, e.options.MaxBodyBytes)
+	}
 
-	ctx.ResponseWriter = rw.(ResponseWriter)
 	ctx.Request = req
+	ctx.options = e.options
 
 	// fast match for static routes
 	if e.options.EnableStaticRouter {
@@ -122,8 +127,6 @@ func (e *Engine) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		ctx.handlers = ctx.endNode.handlers
 	}
 
-	ctx.Environ = make(Environ)
-
 	ctx.handlersLength = len(ctx.handlers)
 
 	ctx.run()
@@ -134,13 +137,26 @@ func (e *Engine) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 // Run start web service
 // Deprecated: please use Run()
 func (e *Engine) ListenAndServe(addr string) error {
-	return http.ListenAndServe(addr, e)
+	if addr == "" {
+		addr = ":http"
+	}
+
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return e.RunListener(l)
 }
 
 // Run start web service with tls
 // Deprecated: please use RunTLS()
 func (e *Engine) ListenAndServeTLS(addr, certFile, keyFile string) error {
-	return http.ListenAndServeTLS(addr, certFile, keyFile, e)
+	if addr == "" {
+		addr = ":https"
+	}
+
+	return e.RunTLS(addr, certFile, keyFile)
 }
 
 // Run start web service
@@ -148,12 +164,43 @@ func (e *Engine) ListenAndServeTLS(addr, certFile, keyFile string) error {
 func (e *Engine) Run(addr ...string) error {
 	wantAddr := resolveAddress(addr)
 
-	return http.ListenAndServe(wantAddr, e)
+	l, err := net.Listen("tcp", wantAddr)
+	if err != nil {
+		return err
+	}
+
+	return e.RunListener(l)
 }
 
 // Run start web service with tls
 func (e *Engine) RunTLS(addr, certFile, keyFile string) error {
-	return http.ListenAndServeTLS(addr, certFile, keyFile, e)
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return e.server().ServeTLS(e.listener(l), certFile, keyFile)
+}
+
+// RunListener start web service on l
+func (e *Engine) RunListener(l net.Listener) error {
+	return e.server().Serve(e.listener(l))
+}
+
+func (e *Engine) server() *http.Server {
+	return &http.Server{
+		Handler:     e,
+		ConnContext: proxyConnContext,
+	}
+}
+
+// listener wraps l with PROXY protocol if WithProxyProtocol
+func (e *Engine) listener(l net.Listener) net.Listener {
+	if e.options.ProxyProtocol != nil {
+		return NewProxyProtocolListener(l, *e.options.ProxyProtocol)
+	}
+
+	return l
 }
 
 func (e *Engine) buildTree() {
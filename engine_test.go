@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -90,6 +91,39 @@ func testRaw(w http.ResponseWriter, req *http.Request) {
 	w.Write([]byte(req.URL.String()))
 }
 
+// newTestEngine returns an Engine which serves h at method and path.
+func newTestEngine(method, path string, h interface{}, opts ...Option) *Engine {
+	r := NewRouter()
+	r.handle(method, path, []interface{}{h})
+
+	return r.Handler(opts...)
+}
+
+// newTestRequest returns a request with body, header is the pairs of key and value.
+func newTestRequest(method, url, body string, header ...string) *http.Request {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		panic(err)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Add(header[i], header[i+1])
+	}
+
+	return req
+}
+
+// serve serves the request of newTestRequest by e.
+func serve(e http.Handler, method, url, body string, header ...string) *httptest.ResponseRecorder {
+	return serveRequest(e, newTestRequest(method, url, body, header...))
+}
+
+func serveRequest(e http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	resp := httptest.NewRecorder()
+	e.ServeHTTP(resp, req)
+
+	return resp
+}
+
 func TestEngineNoRoute(t *testing.T) {
 	router := NewRouter()
 
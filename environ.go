@@ -1,6 +1,7 @@
 package water
 
-// a set of environment variables.
+// a set of environment variables, it is allocated once for the pooled Context
+// and cleared for every request.
 // 存储water的环境变量
 type Environ map[string]interface{}
 
@@ -57,11 +58,7 @@ func (m Environ) Has(name string) bool {
 
 // Set is used to store a new key/value pair exclusively for this context.
 func (c *Context) Set(name string, value interface{}) {
-	if c.Environ.Has(name) {
-		panic("double Environ: " + name)
-	} else {
-		c.Environ[name] = value
-	}
+	c.Environ.Set(name, value)
 }
 
 // Get returns the value for the given name if it exists, otherwise it panics.
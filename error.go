@@ -0,0 +1,54 @@
+package water
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+
+	"github.com/meilihao/water/binding"
+)
+
+// HTTPError is an error which carries the http status code to reply.
+type HTTPError struct {
+	Code    int
+	Message string
+}
+
+// NewHTTPError returns a HTTPError, message defaults to http.StatusText(code).
+func NewHTTPError(code int, message ...string) *HTTPError {
+	e := &HTTPError{
+		Code:    code,
+		Message: http.StatusText(code),
+	}
+	if len(message) > 0 {
+		e.Message = message[0]
+	}
+
+	return e
+}
+
+func (e *HTTPError) Error() string {
+	return e.Message
+}
+
+func (e *HTTPError) StatusCode() int {
+	return e.Code
+}
+
+// statusOfError returns the status code carried by err, default is 500.
+func statusOfError(err error) int {
+	var sc interface{ StatusCode() int }
+	if errors.As(err, &sc) {
+		return sc.StatusCode()
+	}
+
+	return http.StatusInternalServerError
+}
+
+// errorBody is the body of the error replied by Typed, AbortValidation and
+// ValueParser.Abort.
+type errorBody struct {
+	XMLName xml.Name              `json:"-" xml:"error"`
+	Error   string                `json:"error" xml:"message"`
+	Fields  []*binding.FieldError `json:"fields,omitempty" xml:"field"`
+}
@@ -0,0 +1,361 @@
+package water
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/meilihao/logx"
+)
+
+var (
+	contextType        = reflect.TypeOf((*Context)(nil))
+	requestType        = reflect.TypeOf((*http.Request)(nil))
+	responseWriterType = reflect.TypeOf((*http.ResponseWriter)(nil)).Elem()
+	errorType          = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// service is a singleton value or a request-scoped factory.
+type service struct {
+	value   interface{}
+	factory func(*Context) (interface{}, error)
+}
+
+// Provide registers the services by their types, they can be injected into
+// the handlers of r and its groups.
+//
+//	r.Provide(db, cfg)
+//	r.GET("/", func(ctx *water.Context, db *sql.DB, cfg *Config) {})
+func (r *Router) Provide(services ...interface{}) {
+	for _, v := range services {
+		if v == nil {
+			panic("nil service")
+		}
+		r.addService(reflect.TypeOf(v), &service{value: v})
+	}
+}
+
+// ProvideAs registers the service as the interface which ifacePtr points to,
+// e.g. r.ProvideAs(store, (*Store)(nil)).
+func (r *Router) ProvideAs(v interface{}, ifacePtr interface{}) {
+	t := interfaceOf(ifacePtr)
+	if !reflect.TypeOf(v).Implements(t) {
+		panic(fmt.Sprintf("service %T does not implement %s", v, t))
+	}
+
+	r.addService(t, &service{value: v})
+}
+
+// ProvideFactory registers a request-scoped service, factory is
+// func(*Context) T or func(*Context) (T, error), and is called at most once
+// per request. An error aborts the request with statusOfError.
+// factory is called by reflection, ProvideFactoryOf avoids it.
+func (r *Router) ProvideFactory(factory interface{}) {
+	t, s := newFactory(factory)
+	r.addService(t, s)
+}
+
+// ProvideFactoryOf is ProvideFactory without reflection per request.
+//
+//	water.ProvideFactoryOf(r, func(ctx *water.Context) (*User, error) {...})
+func ProvideFactoryOf[T any](r *Router, factory func(*Context) (T, error)) {
+	r.addService(reflect.TypeOf((*T)(nil)).Elem(), &service{factory: func(ctx *Context) (interface{}, error) {
+		return factory(ctx)
+	}})
+}
+
+func (r *Router) addService(t reflect.Type, s *service) {
+	if r.services == nil {
+		r.services = make(map[reflect.Type]*service)
+	}
+	if _, ok := r.services[t]; ok {
+		panic(fmt.Sprintf("double service: %s", t))
+	}
+
+	r.services[t] = s
+}
+
+// WithServices registers the engine-level singletons, the services of
+// routers take precedence over them. It panics on a duplicate type like Provide.
+func WithServices(services ...interface{}) Option {
+	return func(o *options) {
+		if o.Services == nil {
+			o.Services = make(map[reflect.Type]*service)
+		}
+
+		for _, v := range services {
+			if v == nil {
+				panic("nil service")
+			}
+
+			t := reflect.TypeOf(v)
+			if _, ok := o.Services[t]; ok {
+				panic(fmt.Sprintf("double service: %s", t))
+			}
+			o.Services[t] = &service{value: v}
+		}
+	}
+}
+
+func newFactory(factory interface{}) (reflect.Type, *service) {
+	fv := reflect.ValueOf(factory)
+	ft := fv.Type()
+
+	if ft.Kind() != reflect.Func || ft.NumIn() != 1 || ft.In(0) != contextType ||
+		ft.NumOut() < 1 || ft.NumOut() > 2 || (ft.NumOut() == 2 && ft.Out(1) != errorType) {
+		panic(fmt.Sprintf("unsupported service factory: %s", ft))
+	}
+
+	hasErr := ft.NumOut() == 2
+	return ft.Out(0), &service{factory: func(ctx *Context) (interface{}, error) {
+		out := fv.Call([]reflect.Value{reflect.ValueOf(ctx)})
+		if hasErr && !out[1].IsNil() {
+			return nil, out[1].Interface().(error)
+		}
+		return out[0].Interface(), nil
+	}}
+}
+
+func interfaceOf(ifacePtr interface{}) reflect.Type {
+	t := reflect.TypeOf(ifacePtr)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Interface {
+		panic("ifacePtr must be a pointer to interface, e.g. (*Iface)(nil)")
+	}
+
+	return t.Elem()
+}
+
+// lookupService finds the service of t from r to the root, then in o.
+func (r *Router) lookupService(t reflect.Type, o *options) *service {
+	for tmp := r; tmp != nil; tmp = tmp.parent {
+		if s, ok := tmp.services[t]; ok {
+			return s
+		}
+	}
+
+	return o.Services[t]
+}
+
+func (s *service) get(ctx *Context) (interface{}, error) {
+	if s.factory == nil {
+		return s.value, nil
+	}
+
+	if v, ok := ctx.services[s]; ok {
+		return v, nil
+	}
+
+	v, err := s.factory(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if ctx.services == nil {
+		ctx.services = make(map[*service]interface{})
+	}
+	ctx.services[s] = v
+
+	return v, nil
+}
+
+// injectable is a handler made by Inject, its arguments are resolved by Handler().
+type injectable func(r *Router, o *options) Handler
+
+// resolveArg returns the getter of the argument A of a handler, panic if
+// the service of A is not found.
+func resolveArg[A any](r *Router, o *options) func(*Context) (A, error) {
+	t := reflect.TypeOf((*A)(nil)).Elem()
+	switch t {
+	case contextType:
+		return func(ctx *Context) (A, error) { return any(ctx).(A), nil }
+	case requestType:
+		return func(ctx *Context) (A, error) { return any(ctx.Request).(A), nil }
+	case responseWriterType:
+		return func(ctx *Context) (A, error) { return any(ctx.ResponseWriter).(A), nil }
+	}
+
+	s := r.lookupService(t, o)
+	if s == nil {
+		panic(fmt.Sprintf("unresolved service %s", t))
+	}
+
+	if s.factory == nil {
+		v := s.value.(A)
+		return func(*Context) (A, error) { return v, nil }
+	}
+
+	return func(ctx *Context) (A, error) {
+		v, err := s.get(ctx)
+		if err != nil || v == nil {
+			var zero A
+			return zero, err
+		}
+		return v.(A), nil
+	}
+}
+
+// Inject adapts fn with a service argument to a handler, A is resolved by
+// Handler() like the func handlers, but without reflection per request.
+// An error aborts the request with statusOfError.
+//
+//	r.GET("/", water.Inject(func(ctx *water.Context, db *sql.DB) error {...}))
+func Inject[A any](fn func(*Context, A) error) interface{} {
+	return injectable(func(r *Router, o *options) Handler {
+		a := resolveArg[A](r, o)
+
+		return HandlerFunc(func(ctx *Context) {
+			va, err := a(ctx)
+			if err == nil {
+				err = fn(ctx, va)
+			}
+			ctx.abortInject(err)
+		})
+	})
+}
+
+// Inject2 is Inject with two service arguments.
+func Inject2[A, B any](fn func(*Context, A, B) error) interface{} {
+	return injectable(func(r *Router, o *options) Handler {
+		a, b := resolveArg[A](r, o), resolveArg[B](r, o)
+
+		return HandlerFunc(func(ctx *Context) {
+			va, err := a(ctx)
+			if err != nil {
+				ctx.abortInject(err)
+				return
+			}
+			vb, err := b(ctx)
+			if err == nil {
+				err = fn(ctx, va, vb)
+			}
+			ctx.abortInject(err)
+		})
+	})
+}
+
+// Inject3 is Inject with three service arguments.
+func Inject3[A, B, C any](fn func(*Context, A, B, C) error) interface{} {
+	return injectable(func(r *Router, o *options) Handler {
+		a, b, c := resolveArg[A](r, o), resolveArg[B](r, o), resolveArg[C](r, o)
+
+		return HandlerFunc(func(ctx *Context) {
+			va, err := a(ctx)
+			if err != nil {
+				ctx.abortInject(err)
+				return
+			}
+			vb, err := b(ctx)
+			if err != nil {
+				ctx.abortInject(err)
+				return
+			}
+			vc, err := c(ctx)
+			if err == nil {
+				err = fn(ctx, va, vb, vc)
+			}
+			ctx.abortInject(err)
+		})
+	})
+}
+
+// abortInject aborts the request with statusOfError if err is not nil.
+func (ctx *Context) abortInject(err error) {
+	if err != nil && !ctx.Written() {
+		logx.Warn(err)
+		ctx.Abort(statusOfError(err))
+	}
+}
+
+// injectHandler calls the handler by reflection with the arguments resolved
+// by Handler(), it is the slow path of the handlers not made by Inject.
+// Only the lookup of services is done once, every request still allocates
+// the arguments and pays for reflect.Value.Call.
+type injectHandler struct {
+	fn     reflect.Value
+	args   []func(*Context) (reflect.Value, error)
+	hasErr bool
+}
+
+// newInjectHandler converts handler to Handler, and resolves the arguments of
+// the func handler with the services of r, panic if a service is not found.
+// The handlers with service arguments are called by reflection unless made by Inject.
+func newInjectHandler(handler interface{}, r *Router, o *options) Handler {
+	switch h := handler.(type) {
+	case Handler, func(*Context), http.Handler, func(http.ResponseWriter, *http.Request):
+		return newHandler(handler)
+	case func(*Context) error:
+		return HandlerFunc(func(ctx *Context) {
+			ctx.abortInject(h(ctx))
+		})
+	case injectable:
+		return h(r, o)
+	}
+
+	fv := reflect.ValueOf(handler)
+	ft := fv.Type()
+	if ft.Kind() != reflect.Func || ft.NumOut() > 1 || (ft.NumOut() == 1 && ft.Out(0) != errorType) {
+		panic("unsupported handler")
+	}
+
+	h := &injectHandler{
+		fn:     fv,
+		args:   make([]func(*Context) (reflect.Value, error), ft.NumIn()),
+		hasErr: ft.NumOut() == 1,
+	}
+	for i := range h.args {
+		t := ft.In(i)
+		if h.args[i] = resolveValue(t, r, o); h.args[i] == nil {
+			panic(fmt.Sprintf("unresolved service %s for handler %s", t, ft))
+		}
+	}
+
+	return h
+}
+
+// resolveValue returns the getter of the argument t, nil if the service of t is not found.
+func resolveValue(t reflect.Type, r *Router, o *options) func(*Context) (reflect.Value, error) {
+	switch t {
+	case contextType:
+		return func(ctx *Context) (reflect.Value, error) { return reflect.ValueOf(ctx), nil }
+	case requestType:
+		return func(ctx *Context) (reflect.Value, error) { return reflect.ValueOf(ctx.Request), nil }
+	case responseWriterType:
+		return func(ctx *Context) (reflect.Value, error) { return reflect.ValueOf(ctx.ResponseWriter), nil }
+	}
+
+	s := r.lookupService(t, o)
+	if s == nil {
+		return nil
+	}
+
+	if s.factory == nil {
+		v := reflect.ValueOf(s.value)
+		return func(*Context) (reflect.Value, error) { return v, nil }
+	}
+
+	zero := reflect.Zero(t)
+	return func(ctx *Context) (reflect.Value, error) {
+		v, err := s.get(ctx)
+		if err != nil || v == nil {
+			return zero, err
+		}
+		return reflect.ValueOf(v), nil
+	}
+}
+
+func (h *injectHandler) ServeHTTP(ctx *Context) {
+	in := make([]reflect.Value, len(h.args))
+	for i, arg := range h.args {
+		v, err := arg(ctx)
+		if err != nil {
+			ctx.abortInject(err)
+			return
+		}
+		in[i] = v
+	}
+
+	out := h.fn.Call(in)
+	if h.hasErr && !out[0].IsNil() {
+		ctx.abortInject(out[0].Interface().(error))
+	}
+}
@@ -0,0 +1,145 @@
+package water
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type injectConfig struct{ Name string }
+
+type injectGreeter interface{ Greet() string }
+
+type injectHello struct{}
+
+func (injectHello) Greet() string { return "hello" }
+
+type injectRequestID struct{ ID string }
+
+func TestInject(t *testing.T) {
+	Convey("services", t, func() {
+		calls := 0
+
+		r := NewRouter()
+		r.Provide(&injectConfig{Name: "root"})
+		r.ProvideAs(injectHello{}, (*injectGreeter)(nil))
+		r.ProvideFactory(func(ctx *Context) *injectRequestID {
+			calls++
+			return &injectRequestID{ID: ctx.Query("id")}
+		})
+		r.Use(func(ctx *Context, rid *injectRequestID) {
+			ctx.SetHeader("X-Request-Id", rid.ID)
+			ctx.Next()
+		})
+		r.GET("/", func(ctx *Context, cfg *injectConfig, g injectGreeter, rid *injectRequestID) {
+			ctx.String(200, cfg.Name+" "+g.Greet()+" "+rid.ID)
+		})
+
+		g := r.Group("/g")
+		g.Provide(&injectConfig{Name: "group"})
+		g.GET("/x", func(w http.ResponseWriter, req *http.Request, cfg *injectConfig, n int) {
+			fmt.Fprint(w, cfg.Name, n, req.URL.Path)
+		})
+
+		r.GET("/err", func(ctx *Context) error {
+			return NewHTTPError(http.StatusTeapot)
+		})
+
+		e := r.Handler(WithServices(1))
+
+		resp := serve(e, "GET", "/?id=a", "")
+		So(resp.Body.String(), ShouldEqual, "root hello a")
+		So(resp.Header().Get("X-Request-Id"), ShouldEqual, "a")
+		So(calls, ShouldEqual, 1)
+
+		// a new instance for each request
+		resp = serve(e, "GET", "/?id=b", "")
+		So(resp.Body.String(), ShouldEqual, "root hello b")
+		So(calls, ShouldEqual, 2)
+
+		resp = serve(e, "GET", "/g/x", "")
+		So(resp.Body.String(), ShouldEqual, "group1/g/x")
+
+		resp = serve(e, "GET", "/err", "")
+		So(resp.Code, ShouldEqual, http.StatusTeapot)
+	})
+
+	Convey("factory error", t, func() {
+		r := NewRouter()
+		r.ProvideFactory(func(ctx *Context) (*injectRequestID, error) {
+			return nil, NewHTTPError(http.StatusUnauthorized)
+		})
+		r.GET("/", func(ctx *Context, rid *injectRequestID) {
+			ctx.String(200, "ok")
+		})
+
+		resp := serve(r.Handler(), "GET", "/", "")
+		So(resp.Code, ShouldEqual, http.StatusUnauthorized)
+	})
+
+	Convey("Inject", t, func() {
+		calls := 0
+
+		r := NewRouter()
+		r.Provide(&injectConfig{Name: "root"})
+		r.ProvideAs(injectHello{}, (*injectGreeter)(nil))
+		ProvideFactoryOf(r, func(ctx *Context) (*injectRequestID, error) {
+			calls++
+			if ctx.Query("id") == "" {
+				return nil, NewHTTPError(http.StatusUnauthorized)
+			}
+			return &injectRequestID{ID: ctx.Query("id")}, nil
+		})
+		r.Use(Inject(func(ctx *Context, rid *injectRequestID) error {
+			ctx.SetHeader("X-Request-Id", rid.ID)
+			ctx.Next()
+			return nil
+		}))
+		r.GET("/", Inject3(func(ctx *Context, cfg *injectConfig, g injectGreeter, rid *injectRequestID) error {
+			ctx.String(200, cfg.Name+" "+g.Greet()+" "+rid.ID)
+			return nil
+		}))
+		r.GET("/w", Inject2(func(ctx *Context, w http.ResponseWriter, req *http.Request) error {
+			fmt.Fprint(w, req.URL.Path)
+			return nil
+		}))
+		r.GET("/err", Inject(func(ctx *Context, cfg *injectConfig) error {
+			return NewHTTPError(http.StatusTeapot)
+		}))
+		e := r.Handler()
+
+		resp := serve(e, "GET", "/?id=a", "")
+		So(resp.Body.String(), ShouldEqual, "root hello a")
+		So(resp.Header().Get("X-Request-Id"), ShouldEqual, "a")
+		So(calls, ShouldEqual, 1)
+
+		resp = serve(e, "GET", "/w?id=a", "")
+		So(resp.Body.String(), ShouldEqual, "/w")
+
+		resp = serve(e, "GET", "/", "")
+		So(resp.Code, ShouldEqual, http.StatusUnauthorized)
+
+		resp = serve(e, "GET", "/err?id=a", "")
+		So(resp.Code, ShouldEqual, http.StatusTeapot)
+
+		r = NewRouter()
+		r.GET("/", Inject(func(ctx *Context, cfg *injectConfig) error { return nil }))
+		So(func() { r.Handler() }, ShouldPanic)
+	})
+
+	Convey("unresolved service panics at Handler()", t, func() {
+		r := NewRouter()
+		r.GET("/", func(ctx *Context, cfg *injectConfig) {})
+		So(func() { r.Handler() }, ShouldPanic)
+
+		So(func() { WithServices(&injectConfig{}, &injectConfig{})(&options{}) }, ShouldPanic)
+
+		r = NewRouter()
+		g := r.Group("/g")
+		g.Provide(&injectConfig{})
+		r.GET("/", func(ctx *Context, cfg *injectConfig) {})
+		So(func() { r.Handler() }, ShouldPanic)
+	})
+}
@@ -0,0 +1,56 @@
+package water
+
+// Key is a typed key of the request-scoped values in ctx.Environ.
+//
+//	var userKey = water.NewKey[*User]("user")
+//
+//	userKey.Set(ctx, u)
+//	u, ok := userKey.Get(ctx)
+type Key[T any] struct {
+	name string
+}
+
+// NewKey returns a Key of name, the keys of the same name share the value.
+func NewKey[T any](name string) Key[T] {
+	return Key[T]{name: name}
+}
+
+func (k Key[T]) Name() string {
+	return k.name
+}
+
+// Get returns the value of k, false if not set or the value is not a T.
+func (k Key[T]) Get(ctx *Context) (T, bool) {
+	v, ok := ctx.Environ[k.name].(T)
+	return v, ok
+}
+
+// MustGet returns the value of k, panic if not set or the value is not a T.
+func (k Key[T]) MustGet(ctx *Context) T {
+	v, ok := k.Get(ctx)
+	if !ok {
+		panic("Environ not exist: " + k.name)
+	}
+
+	return v
+}
+
+// Set sets the value of k, and overwrites the old one, unlike ctx.Set.
+func (k Key[T]) Set(ctx *Context, v T) {
+	ctx.Environ[k.name] = v
+}
+
+// SetIfAbsent sets the value of k only if it is not set, and reports whether v is set.
+func (k Key[T]) SetIfAbsent(ctx *Context, v T) bool {
+	if ctx.Environ.Has(k.name) {
+		return false
+	}
+	ctx.Environ[k.name] = v
+
+	return true
+}
+
+// Delete removes the value of k.
+func (k Key[T]) Delete(ctx *Context) {
+	delete(ctx.Environ, k.name)
+}
@@ -0,0 +1,63 @@
+package water
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestKey(t *testing.T) {
+	type user struct{ Name string }
+
+	userKey := NewKey[*user]("user")
+	countKey := NewKey[int]("count")
+
+	Convey("Key", t, func() {
+		r := NewRouter()
+		r.Use(func(ctx *Context) {
+			So(ctx.Environ, ShouldBeEmpty)
+			So(ctx.Environ, ShouldNotBeNil)
+
+			_, ok := userKey.Get(ctx)
+			So(ok, ShouldBeFalse)
+			So(func() { userKey.MustGet(ctx) }, ShouldPanic)
+
+			userKey.Set(ctx, &user{Name: "a"})
+			userKey.Set(ctx, &user{Name: "b"})
+			So(userKey.SetIfAbsent(ctx, &user{Name: "c"}), ShouldBeFalse)
+			So(countKey.SetIfAbsent(ctx, 1), ShouldBeTrue)
+
+			ctx.Environ["raw"] = 1
+			env := ctx.Environ
+			env.Set("copied", 2)
+			So(ctx.Get("copied"), ShouldEqual, 2)
+
+			ctx.Next()
+		})
+		r.GET("/", func(ctx *Context) {
+			So(userKey.MustGet(ctx).Name, ShouldEqual, "b")
+			So(ctx.Get("count"), ShouldEqual, 1)
+
+			// the value of another type
+			_, ok := NewKey[string]("count").Get(ctx)
+			So(ok, ShouldBeFalse)
+
+			countKey.Delete(ctx)
+			_, ok = countKey.Get(ctx)
+			So(ok, ShouldBeFalse)
+
+			ctx.String(200, "ok")
+		})
+		e := r.Handler()
+
+		// the pooled Environ is cleared for the next request
+		for i := 0; i < 2; i++ {
+			resp := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/", nil)
+			e.ServeHTTP(resp, req)
+			So(resp.Body.String(), ShouldEqual, "ok")
+		}
+	})
+}
@@ -0,0 +1,23 @@
+package water
+
+import (
+	"net/http"
+)
+
+// BodyLimit limits the request body to max bytes.
+// It replies 413 at once if Content-Length exceeds max, otherwise reading
+// more than max gets *binding.BodyTooLargeError.
+func BodyLimit(max int64) HandlerFunc {
+	return func(ctx *Context) {
+		if ctx.Request.ContentLength > max {
+			ctx.Abort(http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		if ctx.Request.Body != nil {
+			ctx.Request.Body = http.MaxBytesReader(ctx.ResponseWriter, ctx.Request.Body, max)
+		}
+
+		ctx.Next()
+	}
+}
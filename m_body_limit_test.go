@@ -0,0 +1,76 @@
+package water
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/meilihao/water/binding"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBodyLimit(t *testing.T) {
+	type foo struct {
+		Foo string `json:"foo" form:"foo"`
+	}
+
+	// chunked hides the length of body
+	chunked := func(contentType, body string) *http.Request {
+		req := newTestRequest("POST", "/", body, HeaderContentType, contentType)
+		req.ContentLength = -1
+		return req
+	}
+
+	var bindErr error
+	bind := func(ctx *Context) {
+		var v foo
+		if bindErr = ctx.Bind(&v); bindErr != nil {
+			return
+		}
+		ctx.String(200, v.Foo)
+	}
+
+	Convey("WithMaxBodyBytes", t, func() {
+		e := newTestEngine("POST", "/", bind, WithMaxBodyBytes(8))
+
+		resp := serveRequest(e, chunked(MIMEApplicationJSON, `{"foo":"0123456789"}`))
+		So(resp.Code, ShouldEqual, http.StatusRequestEntityTooLarge)
+		So(errors.As(bindErr, new(*binding.BodyTooLargeError)), ShouldBeTrue)
+
+		resp = serveRequest(e, chunked(binding.MIMEPOSTForm, "foo=0123456789"))
+		So(resp.Code, ShouldEqual, http.StatusRequestEntityTooLarge)
+		So(errors.As(bindErr, new(*binding.BodyTooLargeError)), ShouldBeTrue)
+
+		resp = serveRequest(e, chunked(binding.MIMEPOSTForm, "foo=1"))
+		So(resp.Code, ShouldEqual, 200)
+		So(resp.Body.String(), ShouldEqual, "1")
+	})
+
+	Convey("BodyLimit", t, func() {
+		r := NewRouter()
+		r.POST("/", BodyLimit(8), func(ctx *Context) {
+			if _, err := ctx.BodyBytes(); err != nil {
+				return
+			}
+			ctx.String(200, "ok")
+		})
+		e := r.Handler()
+
+		resp := serve(e, "POST", "/", "0123456789", HeaderContentType, MIMETextPlain)
+		So(resp.Code, ShouldEqual, http.StatusRequestEntityTooLarge)
+
+		resp = serveRequest(e, chunked(MIMETextPlain, "0123456789"))
+		So(resp.Code, ShouldEqual, http.StatusRequestEntityTooLarge)
+
+		resp = serve(e, "POST", "/", "0123", HeaderContentType, MIMETextPlain)
+		So(resp.Body.String(), ShouldEqual, "ok")
+	})
+
+	Convey("Typed", t, func() {
+		e := newTestEngine("POST", "/", Typed(func(ctx *Context, req foo) (foo, error) { return req, nil }), WithMaxBodyBytes(8))
+
+		resp := serveRequest(e, chunked(MIMEApplicationJSON, `{"foo":"0123456789"}`))
+		So(resp.Code, ShouldEqual, http.StatusRequestEntityTooLarge)
+		So(resp.Body.String(), ShouldContainSubstring, "too large")
+	})
+}
@@ -1,10 +1,7 @@
 package water
 
 import (
-	"bytes"
 	"fmt"
-	"io"
-	"io/ioutil"
 	"net/http"
 	"strings"
 	"time"
@@ -59,15 +56,11 @@ func Logger() HandlerFunc {
 		start := time.Now()
 		body := ""
 
+		// the body is cached by ctx.Body(), so it can be read again by handlers
 		if LogHttpBody && ctx.Request.Body != nil && strings.Contains(ctx.Request.Header.Get("Content-Type"), "application/json") {
-			safe := &io.LimitedReader{R: ctx.Request.Body, N: 1 << 20}
-			requestbody, _ := ioutil.ReadAll(safe)
-			ctx.Request.Body.Close()
-
-			bf := bytes.NewBuffer(requestbody)
-			ctx.Request.Body = ioutil.NopCloser(bf)
-
-			body = "\n" + string(requestbody)
+			if requestbody, err := ctx.Body(); err == nil {
+				body = "\n" + string(requestbody)
+			}
 		}
 
 		ctx.Next()
@@ -76,9 +69,9 @@ func Logger() HandlerFunc {
 		logx.Infof("%s %v |%s| %13v | %16s | %7s %s%s",
 			logPrefix(ctx.Request),
 			start.Format(LogTimeFormat),
-			logStatus(ctx.status),
+			logStatus(ctx.Status()),
 			time.Now().Sub(start),
-			ctx.RealIp(),
+			ctx.ClientIP(),
 			ctx.Request.Method,
 			ctx.Request.URL.String(),
 			body,
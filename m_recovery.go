@@ -26,7 +26,7 @@ func Recovery() HandlerFunc {
 					content += fmt.Sprintf("%v %v", file, line)
 				}
 
-				if !ctx.written {
+				if !ctx.Written() {
 					ctx.WriteHeader(http.StatusInternalServerError)
 				}
 
@@ -36,7 +36,7 @@ func Recovery() HandlerFunc {
 					ctx.String(http.StatusInternalServerError, content)
 				}
 			} else {
-				if !ctx.written {
+				if !ctx.Written() {
 					ctx.WriteHeader(http.StatusOK)
 				}
 			}
@@ -0,0 +1,242 @@
+package water
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"time"
+
+	"github.com/meilihao/logx"
+)
+
+// Store loads and saves the values of sessions.
+// id is the value of session cookie, and is empty for a new session.
+type Store interface {
+	// Load returns the values of id, nil if not found or expired.
+	Load(id string) (map[string]interface{}, error)
+	// Save stores values for maxAge, and returns the id to put into the cookie.
+	Save(id string, values map[string]interface{}, maxAge time.Duration) (string, error)
+	// Delete removes the session of id.
+	Delete(id string) error
+}
+
+// SessionConfig defines the config for Sessions middleware.
+type SessionConfig struct {
+	// CookieName is the name of session cookie.
+	// Optional. Default value "water_session".
+	CookieName string
+	// Optional. Default value "/".
+	Path   string
+	Domain string
+	// MaxAge is the lifetime of cookie and the stored session.
+	// Optional. Default value 24h.
+	MaxAge   time.Duration
+	Secure   bool
+	HttpOnly bool
+	SameSite http.SameSite
+}
+
+var (
+	// DefaultSessionConfig is the default Sessions middleware config.
+	DefaultSessionConfig = SessionConfig{
+		CookieName: "water_session",
+		Path:       "/",
+		MaxAge:     24 * time.Hour,
+		HttpOnly:   true,
+		SameSite:   http.SameSiteLaxMode,
+	}
+)
+
+const sessionFlashPrefix = "_flash."
+
+// Sessions loads the session lazily on the first access of ctx.Session(),
+// and saves it just before the response is written.
+func Sessions(store Store, config ...SessionConfig) HandlerFunc {
+	c := DefaultSessionConfig
+	if len(config) > 0 {
+		c = config[0]
+	}
+	if c.CookieName == "" {
+		c.CookieName = DefaultSessionConfig.CookieName
+	}
+	if c.Path == "" {
+		c.Path = DefaultSessionConfig.Path
+	}
+	if c.MaxAge <= 0 {
+		c.MaxAge = DefaultSessionConfig.MaxAge
+	}
+
+	return func(ctx *Context) {
+		s := &Session{
+			ctx:    ctx,
+			store:  store,
+			config: &c,
+		}
+		ctx.session = s
+		ctx.OnBeforeWrite(s.save)
+
+		ctx.Next()
+
+		if !ctx.Written() {
+			s.save()
+		}
+	}
+}
+
+// Session returns the session of current request, panic if Sessions middleware is not used.
+func (ctx *Context) Session() *Session {
+	if ctx.session == nil {
+		panic("Session not exist: use Sessions middleware")
+	}
+
+	return ctx.session
+}
+
+// Session is the session of a request, it is not safe for concurrent use.
+type Session struct {
+	ctx    *Context
+	store  Store
+	config *SessionConfig
+
+	id     string
+	values map[string]interface{}
+
+	loaded    bool
+	changed   bool
+	destroyed bool
+	saved     bool
+	err       error
+}
+
+func (s *Session) load() {
+	if s.loaded {
+		return
+	}
+	s.loaded = true
+
+	if c, err := s.ctx.Request.Cookie(s.config.CookieName); err == nil && c.Value != "" {
+		s.values, s.err = s.store.Load(c.Value)
+		if s.values != nil {
+			s.id = c.Value
+		}
+	}
+	if s.values == nil {
+		s.values = make(map[string]interface{})
+	}
+}
+
+// ID returns the id of session, empty for a new session.
+func (s *Session) ID() string {
+	s.load()
+	return s.id
+}
+
+// Get returns the value of key, nil if not exist.
+func (s *Session) Get(key string) interface{} {
+	s.load()
+	return s.values[key]
+}
+
+func (s *Session) Set(key string, v interface{}) {
+	s.load()
+	s.values[key] = v
+	s.changed = true
+}
+
+func (s *Session) Delete(key string) {
+	s.load()
+	if _, ok := s.values[key]; ok {
+		delete(s.values, key)
+		s.changed = true
+	}
+}
+
+// SetFlash sets a value which can be read only once by Flash, e.g. in the next request.
+func (s *Session) SetFlash(key string, v interface{}) {
+	s.Set(sessionFlashPrefix+key, v)
+}
+
+// Flash returns the flash value of key and deletes it.
+func (s *Session) Flash(key string) interface{} {
+	v := s.Get(sessionFlashPrefix + key)
+	s.Delete(sessionFlashPrefix + key)
+	return v
+}
+
+// Regenerate keeps the values under a new id, e.g. after login to prevent session fixation.
+func (s *Session) Regenerate() error {
+	s.load()
+	if s.id != "" {
+		if err := s.store.Delete(s.id); err != nil {
+			return err
+		}
+		s.id = ""
+	}
+	s.changed = true
+
+	return nil
+}
+
+// Destroy deletes the session from the store and expires the cookie.
+func (s *Session) Destroy() error {
+	s.load()
+	s.values = make(map[string]interface{})
+	s.changed = false
+	s.destroyed = true
+
+	if s.id != "" {
+		id := s.id
+		s.id = ""
+		return s.store.Delete(id)
+	}
+
+	return nil
+}
+
+// Err returns the error of loading session.
+func (s *Session) Err() error {
+	return s.err
+}
+
+func (s *Session) save() {
+	if s.saved || !s.loaded || !(s.changed || s.destroyed) {
+		return
+	}
+	s.saved = true
+
+	if s.destroyed && !s.changed {
+		s.setCookie("", -1)
+		return
+	}
+
+	id, err := s.store.Save(s.id, s.values, s.config.MaxAge)
+	if err != nil {
+		logx.Warn(err)
+		return
+	}
+	s.id = id
+	s.setCookie(id, int(s.config.MaxAge/time.Second))
+}
+
+func (s *Session) setCookie(value string, maxAge int) {
+	http.SetCookie(s.ctx.ResponseWriter, &http.Cookie{
+		Name:     s.config.CookieName,
+		Value:    value,
+		Path:     s.config.Path,
+		Domain:   s.config.Domain,
+		MaxAge:   maxAge,
+		Secure:   s.config.Secure,
+		HttpOnly: s.config.HttpOnly,
+		SameSite: s.config.SameSite,
+	})
+}
+
+// newSessionID returns a random id of 32 bytes.
+func newSessionID() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,134 @@
+package water
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func newSessionEngine(store Store) *Engine {
+	r := NewRouter()
+	r.Use(Sessions(store))
+	r.GET("/set", func(ctx *Context) {
+		ctx.Session().Set("name", ctx.Query("name"))
+		ctx.Session().SetFlash("msg", "hello")
+		ctx.String(200, "ok")
+	})
+	r.GET("/get", func(ctx *Context) {
+		name, _ := ctx.Session().Get("name").(string)
+		msg, _ := ctx.Session().Flash("msg").(string)
+		ctx.String(200, name+"|"+msg)
+	})
+	r.GET("/regenerate", func(ctx *Context) {
+		So(ctx.Session().Regenerate(), ShouldBeNil)
+	})
+	r.GET("/destroy", func(ctx *Context) {
+		So(ctx.Session().Destroy(), ShouldBeNil)
+		ctx.String(200, "ok")
+	})
+	r.GET("/none", func(ctx *Context) {
+		ctx.String(200, "ok")
+	})
+
+	return r.Handler()
+}
+
+func serveSession(e *Engine, url string, cookie *http.Cookie) (*httptest.ResponseRecorder, *http.Cookie) {
+	req := newTestRequest("GET", url, "")
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	resp := serveRequest(e, req)
+
+	for _, c := range resp.Result().Cookies() {
+		if c.Name == DefaultSessionConfig.CookieName {
+			return resp, c
+		}
+	}
+
+	return resp, nil
+}
+
+func TestSessions(t *testing.T) {
+	for name, store := range map[string]Store{
+		"MemoryStore": NewMemoryStore(0),
+		"CookieStore": NewCookieStore([]byte("secret")),
+	} {
+		e := newSessionEngine(store)
+
+		Convey(name, t, func() {
+			_, c := serveSession(e, "/none", nil)
+			So(c, ShouldBeNil)
+
+			_, c = serveSession(e, "/set?name=water", nil)
+			So(c, ShouldNotBeNil)
+			So(c.HttpOnly, ShouldBeTrue)
+			So(c.MaxAge, ShouldEqual, 86400)
+
+			resp, c2 := serveSession(e, "/get", c)
+			So(resp.Body.String(), ShouldEqual, "water|hello")
+
+			// flash is read once
+			resp, _ = serveSession(e, "/get", c2)
+			So(resp.Body.String(), ShouldEqual, "water|")
+
+			_, c3 := serveSession(e, "/regenerate", c2)
+			So(c3, ShouldNotBeNil)
+			resp, _ = serveSession(e, "/get", c3)
+			So(resp.Body.String(), ShouldEqual, "water|")
+
+			_, c4 := serveSession(e, "/destroy", c3)
+			So(c4.MaxAge, ShouldEqual, -1)
+		})
+	}
+
+	Convey("MemoryStore invalidates the old id", t, func() {
+		store := NewMemoryStore(0)
+		e := newSessionEngine(store)
+
+		_, c := serveSession(e, "/set?name=water", nil)
+		_, c2 := serveSession(e, "/regenerate", c)
+		So(c2.Value, ShouldNotEqual, c.Value)
+		resp, _ := serveSession(e, "/get", c)
+		So(resp.Body.String(), ShouldEqual, "|")
+
+		serveSession(e, "/destroy", c2)
+		resp, _ = serveSession(e, "/get", c2)
+		So(resp.Body.String(), ShouldEqual, "|")
+		So(store.Len(), ShouldEqual, 0)
+	})
+
+	Convey("CookieStore rejects tampered cookie", t, func() {
+		store := NewCookieStore([]byte("new"), []byte("old"))
+		old := NewCookieStore([]byte("old"))
+
+		id, err := old.Save("", map[string]interface{}{"a": 1}, time.Minute)
+		So(err, ShouldBeNil)
+
+		v, err := store.Load(id)
+		So(err, ShouldBeNil)
+		So(v["a"], ShouldEqual, 1)
+
+		_, err = store.Load("x" + id)
+		So(err, ShouldEqual, ErrSessionCookieInvalid)
+
+		id, _ = store.Save("", map[string]interface{}{"a": 1}, -time.Minute)
+		v, err = store.Load(id)
+		So(err, ShouldBeNil)
+		So(v, ShouldBeNil)
+	})
+
+	Convey("MemoryStore sweeps the expired sessions", t, func() {
+		store := NewMemoryStore(time.Millisecond)
+		defer store.Close()
+
+		store.Save("", map[string]interface{}{"a": 1}, time.Millisecond)
+		store.Save("", map[string]interface{}{"a": 1}, time.Hour)
+
+		time.Sleep(20 * time.Millisecond)
+		So(store.Len(), ShouldEqual, 1)
+	})
+}
@@ -0,0 +1,258 @@
+package water
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrNotAcceptable is returned by ctx.Negotiate when no offer matches the request.
+var ErrNotAcceptable = errors.New("water: not acceptable")
+
+// format names for Offers.FormatQuery
+var negotiateFormats = map[string]string{
+	"json": MIMEApplicationJSON,
+	"xml":  MIMEApplicationXML,
+	"html": MIMETextHTML,
+	"text": MIMETextPlain,
+	"txt":  MIMETextPlain,
+}
+
+// Offers are the candidates of ctx.Negotiate, a zero field is not offered.
+type Offers struct {
+	JSON interface{}
+	XML  interface{}
+	// HTML is the template name rendered by ctx.HTML with HTMLData
+	HTML     string
+	HTMLData interface{}
+	Text     string
+
+	// FormatQuery is the name of the query param which overrides the Accept
+	// header by json, xml, html or text, e.g. "format" for "?format=json".
+	// "" means not overridden.
+	FormatQuery string
+}
+
+func (o *Offers) offered() []string {
+	ls := make([]string, 0, 4)
+	if o.JSON != nil {
+		ls = append(ls, MIMEApplicationJSON)
+	}
+	if o.XML != nil {
+		ls = append(ls, MIMEApplicationXML)
+	}
+	if o.HTML != "" {
+		ls = append(ls, MIMETextHTML)
+	}
+	if o.Text != "" {
+		ls = append(ls, MIMETextPlain)
+	}
+
+	return ls
+}
+
+// Negotiate renders the offer which the client prefers, reply 406 if no offer matches.
+// An error response(code >= 400) falls back to JSON with code instead of 406.
+// use:
+//
+//	ctx.Negotiate(200, water.Offers{JSON: v, XML: v, HTML: "user", HTMLData: v})
+func (ctx *Context) Negotiate(code int, offers Offers) error {
+	offered := offers.offered()
+
+	format := ctx.negotiateFormat(offers.FormatQuery, offered)
+	if format == "" && code >= http.StatusBadRequest && offers.JSON != nil {
+		format = MIMEApplicationJSON
+	}
+
+	switch format {
+	case MIMEApplicationJSON:
+		return ctx.JSON(code, offers.JSON)
+	case MIMEApplicationXML:
+		return ctx.XML(code, offers.XML)
+	case MIMETextHTML:
+		ctx.HTML(code, offers.HTML, offers.HTMLData)
+	case MIMETextPlain:
+		ctx.String(code, offers.Text)
+	default:
+		ctx.Abort(http.StatusNotAcceptable)
+		return ErrNotAcceptable
+	}
+
+	return nil
+}
+
+// NegotiateFormat returns the offered MIME type which the client prefers,
+// returns "" if no offer matches.
+// An Accept-Charset without utf-8 matches nothing since water always responds in utf-8.
+// It adds "Vary: Accept" to the response.
+func (ctx *Context) NegotiateFormat(offered ...string) string {
+	return ctx.negotiateFormat("", offered)
+}
+
+// negotiateFormat is NegotiateFormat with the query param formatQuery
+// overriding the Accept header, see Offers.FormatQuery.
+func (ctx *Context) negotiateFormat(formatQuery string, offered []string) string {
+	addVary(ctx.Header(), HeaderAccept)
+
+	if len(offered) == 0 {
+		return ""
+	}
+
+	if !acceptsCharset(ctx.GetHeader(HeaderAcceptCharset), "utf-8") {
+		return ""
+	}
+
+	if formatQuery == "" {
+		return negotiate(ctx.GetHeader(HeaderAccept), offered)
+	}
+
+	if f := ctx.Request.URL.Query().Get(formatQuery); f != "" {
+		want := negotiateFormats[strings.ToLower(f)]
+		for _, v := range offered {
+			if v == want {
+				return v
+			}
+		}
+		return ""
+	}
+
+	return negotiate(ctx.GetHeader(HeaderAccept), offered)
+}
+
+// acceptSpec is a media range from the Accept header
+type acceptSpec struct {
+	typ, sub string
+	params   int // count of params except q
+	q        float64
+}
+
+// specificity: type/sub;params > type/sub > type/* > */*
+func (s acceptSpec) specificity() int {
+	switch {
+	case s.typ == "*":
+		return 0
+	case s.sub == "*":
+		return 1
+	default:
+		return 2 + s.params
+	}
+}
+
+func (s acceptSpec) match(typ, sub string) bool {
+	return (s.typ == "*" || s.typ == typ) && (s.sub == "*" || s.sub == sub)
+}
+
+// parseAccept parses header like Accept, order by q and specificity, both desc.
+// the invalid media range is ignored.
+func parseAccept(header string) []acceptSpec {
+	ls := make([]acceptSpec, 0, 4)
+
+	for _, part := range strings.Split(header, ",") {
+		mediaRange, params := head(part, ";")
+
+		s := acceptSpec{q: 1}
+		s.typ, s.sub = head(strings.ToLower(strings.TrimSpace(mediaRange)), "/")
+		if s.typ == "" || (s.typ == "*" && s.sub != "*" && s.sub != "") {
+			continue
+		}
+		if s.sub == "" { // for Accept-Charset, "utf-8" -> "utf-8/*"
+			s.sub = "*"
+		}
+
+		valid := true
+		for params != "" {
+			var p string
+			p, params = head(params, ";")
+
+			k, v := head(strings.TrimSpace(p), "=")
+			if strings.ToLower(strings.TrimSpace(k)) != "q" {
+				s.params++
+				continue
+			}
+
+			q, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil || q < 0 || q > 1 {
+				valid = false
+				break
+			}
+			s.q = q
+		}
+		if valid {
+			ls = append(ls, s)
+		}
+	}
+
+	sort.SliceStable(ls, func(i, j int) bool {
+		if ls[i].q != ls[j].q {
+			return ls[i].q > ls[j].q
+		}
+		return ls[i].specificity() > ls[j].specificity()
+	})
+
+	return ls
+}
+
+// quality returns the q of the most specific media range which matches mime,
+// returns 0 if nothing matches.
+func quality(specs []acceptSpec, mime string) float64 {
+	typ, sub := head(strings.ToLower(filterFlags(mime)), "/")
+
+	q, specificity := 0.0, -1
+	for _, s := range specs {
+		if s.match(typ, sub) && s.specificity() > specificity {
+			q, specificity = s.q, s.specificity()
+		}
+	}
+
+	return q
+}
+
+// negotiate returns the offer with the highest quality, the former offer wins
+// the tie. Empty header accepts everything.
+func negotiate(header string, offered []string) string {
+	if strings.TrimSpace(header) == "" {
+		return offered[0]
+	}
+
+	specs := parseAccept(header)
+
+	best, bestQ := "", 0.0
+	for _, v := range offered {
+		if q := quality(specs, v); q > bestQ {
+			best, bestQ = v, q
+		}
+	}
+
+	return best
+}
+
+// acceptsCharset reports whether charset is acceptable by the Accept-Charset header.
+func acceptsCharset(header, charset string) bool {
+	if strings.TrimSpace(header) == "" {
+		return true
+	}
+
+	return quality(parseAccept(header), charset+"/*") > 0
+}
+
+func addVary(h http.Header, name string) {
+	for _, v := range h.Values(HeaderVary) {
+		for _, f := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(f), name) {
+				return
+			}
+		}
+	}
+
+	h.Add(HeaderVary, name)
+}
+
+func head(str, sep string) (string, string) {
+	idx := strings.Index(str, sep)
+	if idx < 0 {
+		return str, ""
+	}
+	return str[:idx], str[idx+len(sep):]
+}
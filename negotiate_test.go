@@ -0,0 +1,76 @@
+package water
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseAccept(t *testing.T) {
+	Convey("order by q and specificity", t, func() {
+		specs := parseAccept("*/*;q=0.1, text/*, text/html;level=1, application/json;q=0.9, bad;q=x")
+		So(len(specs), ShouldEqual, 4)
+		So(specs[0].typ+"/"+specs[0].sub, ShouldEqual, "text/html")
+		So(specs[1].typ+"/"+specs[1].sub, ShouldEqual, "text/*")
+		So(specs[2].typ+"/"+specs[2].sub, ShouldEqual, "application/json")
+		So(specs[3].typ+"/"+specs[3].sub, ShouldEqual, "*/*")
+	})
+
+	Convey("negotiate", t, func() {
+		offered := []string{MIMEApplicationJSON, MIMEApplicationXML, MIMETextHTML}
+
+		So(negotiate("", offered), ShouldEqual, MIMEApplicationJSON)
+		So(negotiate("application/xml", offered), ShouldEqual, MIMEApplicationXML)
+		So(negotiate("text/*;q=0.5, application/json;q=0.4", offered), ShouldEqual, MIMETextHTML)
+		So(negotiate("*/*;q=0.5, application/json;q=0", offered), ShouldEqual, MIMEApplicationXML)
+		So(negotiate("image/png", offered), ShouldEqual, "")
+	})
+
+	Convey("Accept-Charset", t, func() {
+		So(acceptsCharset("", "utf-8"), ShouldBeTrue)
+		So(acceptsCharset("iso-8859-1, UTF-8;q=0.5", "utf-8"), ShouldBeTrue)
+		So(acceptsCharset("iso-8859-1, *;q=0.1", "utf-8"), ShouldBeTrue)
+		So(acceptsCharset("iso-8859-1", "utf-8"), ShouldBeFalse)
+	})
+}
+
+func TestNegotiate(t *testing.T) {
+	Convey("ctx.Negotiate", t, func() {
+		r := NewRouter()
+		r.GET("/", func(ctx *Context) {
+			ctx.Negotiate(http.StatusOK, Offers{JSON: H{"a": 1}, Text: "a=1", FormatQuery: "format"})
+		})
+		r.GET("/plain", func(ctx *Context) {
+			ctx.Negotiate(http.StatusOK, Offers{JSON: H{"a": 1}, Text: "a=1"})
+		})
+		r.GET("/error", func(ctx *Context) {
+			ctx.Negotiate(http.StatusUnprocessableEntity, Offers{JSON: H{"error": "x"}, XML: H{"error": "x"}})
+		})
+		e := r.Handler()
+
+		resp := serve(e, "GET", "http://localhost:8080/", "", HeaderAccept, "text/plain, application/json;q=0.8")
+		So(resp.Code, ShouldEqual, http.StatusOK)
+		So(resp.Body.String(), ShouldEqual, "a=1")
+		So(resp.Header().Get(HeaderVary), ShouldEqual, HeaderAccept)
+
+		resp = serve(e, "GET", "http://localhost:8080/?format=json", "", HeaderAccept, "text/plain")
+		So(resp.Body.String(), ShouldEqual, `{"a":1}`+"\n")
+
+		resp = serve(e, "GET", "http://localhost:8080/?format=xml", "", HeaderAccept, "")
+		So(resp.Code, ShouldEqual, http.StatusNotAcceptable)
+
+		resp = serve(e, "GET", "http://localhost:8080/", "", HeaderAccept, "image/*")
+		So(resp.Code, ShouldEqual, http.StatusNotAcceptable)
+
+		// the query is not an override unless Offers.FormatQuery
+		resp = serve(e, "GET", "http://localhost:8080/plain?format=csv", "", HeaderAccept, "text/plain")
+		So(resp.Code, ShouldEqual, http.StatusOK)
+		So(resp.Body.String(), ShouldEqual, "a=1")
+
+		// an error response falls back to JSON
+		resp = serve(e, "GET", "http://localhost:8080/error", "", HeaderAccept, "text/html")
+		So(resp.Code, ShouldEqual, http.StatusUnprocessableEntity)
+		So(resp.Body.String(), ShouldEqual, `{"error":"x"}`+"\n")
+	})
+}
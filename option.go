@@ -1,13 +1,33 @@
 package water
 
+import (
+	"net"
+	"reflect"
+
+	"github.com/meilihao/water/binding"
+)
+
 type options struct {
 	EnableStaticRouter bool
 	NoFoundHandlers    []Handler
 	MaxMultipartMemory int64
+	MaxBodyBytes       int64
+	BodyCacheLimit     int64
+	CookiePolicy       CookiePolicy
+	Services           map[reflect.Type]*service
+	TrustedProxies     []*net.IPNet
+	ProxyProtocol      *ProxyProtocolConfig
+	Validator          binding.StructValidater
 }
 
 type Option func(*options)
 
+// defaultOptions is the options without any Option.
+var defaultOptions = options{
+	BodyCacheLimit: DefaultBodyCacheLimit,
+	CookiePolicy:   CookiePolicy{Path: "/"},
+}
+
 // WithStaticRouter for the scene of multi status route
 // 适用于多静态路由的场景
 func WithStaticRouter(enable bool) Option {
@@ -17,7 +37,7 @@ func WithStaticRouter(enable bool) Option {
 }
 
 // WithNoFoundHandlers the handler for no match route, example: vue spa
-// code=404, can use middleware
+// code=404, can use middleware. The services of Provide are not injected into them.
 func WithNoFoundHandlers(hs ...interface{}) Option {
 	if len(hs) == 0 {
 		panic("no NoFoundHandlers")
@@ -34,3 +54,39 @@ func WithMaxMultipartMemory(max int64) Option {
 		o.MaxMultipartMemory = max
 	}
 }
+
+// WithMaxBodyBytes limits the request body of all routes to max bytes,
+// reading more gets *binding.BodyTooLargeError and replies 413.
+// Use BodyLimit() for the route or group.
+func WithMaxBodyBytes(max int64) Option {
+	return func(o *options) {
+		o.MaxBodyBytes = max
+	}
+}
+
+// WithBodyCacheLimit sets the max bytes cached by ctx.Body(),
+// default is DefaultBodyCacheLimit.
+func WithBodyCacheLimit(max int64) Option {
+	return func(o *options) {
+		o.BodyCacheLimit = max
+	}
+}
+
+// WithCookiePolicy sets the default attributes of the cookies set by ctx.
+func WithCookiePolicy(p CookiePolicy) Option {
+	return func(o *options) {
+		o.CookiePolicy = p
+	}
+}
+
+// WithValidator makes the engine validate the bound obj by v instead of
+// binding.Validator, so engines can carry different rules.
+//
+//	v := binding.NewValidator("binding")
+//	v.RegisterRule("even", even)
+//	admin := r.Handler(water.WithValidator(v))
+func WithValidator(v binding.StructValidater) Option {
+	return func(o *options) {
+		o.Validator = v
+	}
+}
@@ -16,6 +16,16 @@ func (ctx *Context) Param(key string) string {
 	return ctx.Params.String(key)
 }
 
+// toForm converts p to the form used by binding.MapForm.
+func (p Params) toForm() map[string][]string {
+	m := make(map[string][]string, len(p))
+	for k, v := range p {
+		m[k] = []string{v}
+	}
+
+	return m
+}
+
 // String returns value by given param name.
 // panic if param not exits
 func (p Params) String(name string) string {
@@ -0,0 +1,286 @@
+package water
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// WithTrustedProxies sets the proxies whose forwarded headers (Forwarded,
+// X-Forwarded-For, X-Forwarded-Proto, X-Forwarded-Host and X-Real-IP) are honoured
+// by ctx.ClientIP(), ctx.Scheme() and ctx.Host(). A cidr can be a single ip.
+// It panics on invalid cidr.
+func WithTrustedProxies(cidrs ...string) Option {
+	nets, err := parseCIDRs(cidrs)
+	if err != nil {
+		panic("invalid trusted proxy: " + err.Error())
+	}
+
+	return func(o *options) {
+		o.TrustedProxies = nets
+	}
+}
+
+// parseCIDRs parses the cidrs, a single ip is taken as /32 or /128.
+func parseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, c := range cidrs {
+		if !strings.Contains(c, "/") {
+			if ip := net.ParseIP(c); ip != nil && ip.To4() != nil {
+				c += "/32"
+			} else {
+				c += "/128"
+			}
+		}
+
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, n)
+	}
+
+	return nets, nil
+}
+
+func isTrustedProxy(trusted []*net.IPNet, ip net.IP) bool {
+	for _, n := range trusted {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// forwardedHop is a hop of the forwarded chain, the fields are empty if unknown.
+type forwardedHop struct {
+	ip     string
+	scheme string
+	host   string
+}
+
+// ClientIP returns the ip of client. It walks the forwarded chain from the right,
+// and stops at the first hop which is not a trusted proxy.
+func (ctx *Context) ClientIP() string {
+	return ctx.forwarded().ip
+}
+
+// Scheme returns "http" or "https" which the client used.
+func (ctx *Context) Scheme() string {
+	return ctx.forwarded().scheme
+}
+
+// Host returns the host which the client requested.
+func (ctx *Context) Host() string {
+	return ctx.forwarded().host
+}
+
+// BaseURL returns scheme://host which the client requested, e.g. https://example.com.
+func (ctx *Context) BaseURL() string {
+	f := ctx.forwarded()
+	return f.scheme + "://" + f.host
+}
+
+func (ctx *Context) forwarded() *forwardedHop {
+	if ctx.fwdParsed {
+		return &ctx.fwd
+	}
+	ctx.fwdParsed = true
+	ctx.fwd = resolveForwarded(ctx.Request, ctx.options.TrustedProxies)
+
+	return &ctx.fwd
+}
+
+// resolveForwarded resolves the client of req, only the forwarded headers
+// from the trusted proxies are honoured.
+func resolveForwarded(req *http.Request, trusted []*net.IPNet) forwardedHop {
+	client := forwardedHop{
+		ip:     remoteIP(req.RemoteAddr),
+		scheme: "http",
+		host:   req.Host,
+	}
+	if req.TLS != nil {
+		client.scheme = "https"
+	}
+
+	if ip := net.ParseIP(client.ip); ip == nil || !isTrustedProxy(trusted, ip) {
+		return client
+	}
+
+	hops := forwardedHops(req)
+	if len(hops) == 0 {
+		if ip := net.ParseIP(strings.TrimSpace(req.Header.Get(HeaderXRealIP))); ip != nil {
+			client.ip = ip.String()
+		}
+		return client
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := parseForwardedNode(hops[i].ip)
+		if ip == nil { // unknown or obfuscated
+			break
+		}
+
+		client.ip = ip.String()
+		if hops[i].scheme == "http" || hops[i].scheme == "https" {
+			client.scheme = hops[i].scheme
+		}
+		if hops[i].host != "" {
+			client.host = hops[i].host
+		}
+
+		if !isTrustedProxy(trusted, ip) {
+			break
+		}
+	}
+
+	return client
+}
+
+// forwardedHops returns the hops from Forwarded, or X-Forwarded-For if no Forwarded.
+func forwardedHops(req *http.Request) []forwardedHop {
+	if vs := req.Header.Values(HeaderForwarded); len(vs) > 0 {
+		return parseForwarded(strings.Join(vs, ","))
+	}
+
+	vs := req.Header.Values(HeaderXForwardedFor)
+	if len(vs) == 0 {
+		return nil
+	}
+
+	ips := strings.Split(strings.Join(vs, ","), ",")
+	schemes := alignForwarded(req.Header.Values(HeaderXForwardedProto), len(ips))
+	hosts := alignForwarded(req.Header.Values(HeaderXForwardedHost), len(ips))
+
+	hops := make([]forwardedHop, len(ips))
+	for i, ip := range ips {
+		hops[i] = forwardedHop{
+			ip:     strings.TrimSpace(ip),
+			scheme: strings.ToLower(schemes[i]),
+			host:   hosts[i],
+		}
+	}
+
+	return hops
+}
+
+// alignForwarded aligns the values of X-Forwarded-Proto or X-Forwarded-Host
+// with the n hops of X-Forwarded-For. Each proxy appends the value for the
+// hop it appends, so they are paired by position if both have n entries,
+// otherwise every hop gets the rightmost value, which is the one from the
+// nearest proxy, the leftmost one may be set by the client.
+func alignForwarded(vs []string, n int) []string {
+	ls := make([]string, n)
+	if len(vs) == 0 {
+		return ls
+	}
+
+	vs = strings.Split(strings.Join(vs, ","), ",")
+	for i := range ls {
+		if len(vs) == n {
+			ls[i] = strings.TrimSpace(vs[i])
+		} else {
+			ls[i] = strings.TrimSpace(vs[len(vs)-1])
+		}
+	}
+
+	return ls
+}
+
+// parseForwarded parses the Forwarded header of RFC 7239, e.g.
+// for=192.0.2.60;proto=http;by=203.0.113.43, for="[2001:db8:cafe::17]:4711"
+func parseForwarded(v string) []forwardedHop {
+	var hops []forwardedHop
+
+	for _, elem := range splitQuoted(v, ',') {
+		var hop forwardedHop
+
+		for _, pair := range splitQuoted(elem, ';') {
+			i := strings.IndexByte(pair, '=')
+			if i < 0 {
+				continue
+			}
+
+			value := unquote(strings.TrimSpace(pair[i+1:]))
+			switch strings.ToLower(strings.TrimSpace(pair[:i])) {
+			case "for":
+				hop.ip = value
+			case "proto":
+				hop.scheme = strings.ToLower(value)
+			case "host":
+				hop.host = value
+			}
+		}
+
+		hops = append(hops, hop)
+	}
+
+	return hops
+}
+
+// parseForwardedNode parses the node of "for", e.g. 192.0.2.43:47011, [2001:db8::1]:4711,
+// returns nil for "unknown" and the obfuscated identifier.
+func parseForwardedNode(node string) net.IP {
+	if strings.HasPrefix(node, "[") {
+		if i := strings.IndexByte(node, ']'); i > 0 {
+			return net.ParseIP(node[1:i])
+		}
+		return nil
+	}
+
+	if strings.Count(node, ":") == 1 {
+		node = node[:strings.IndexByte(node, ':')]
+	}
+
+	return net.ParseIP(node)
+}
+
+func remoteIP(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+
+	return addr
+}
+
+// splitQuoted splits s by sep outside the quoted-string.
+func splitQuoted(s string, sep byte) []string {
+	var ls []string
+
+	quoted, start := false, 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && quoted:
+			i++
+		case s[i] == '"':
+			quoted = !quoted
+		case s[i] == sep && !quoted:
+			ls = append(ls, s[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(ls, s[start:])
+}
+
+func unquote(s string) string {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s
+	}
+
+	s = s[1 : len(s)-1]
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,400 @@
+package water
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PROXY protocol, see https://www.haproxy.org/download/2.8/doc/proxy-protocol.txt
+var (
+	proxyV1Prefix  = []byte("PROXY ")
+	proxyV2Sig     = []byte("\r\n\r\n\x00\r\nQUIT\n")
+	ErrProxyHeader = errors.New("water: invalid PROXY protocol header")
+)
+
+const (
+	proxyV1MaxLen = 107
+
+	// DefaultProxyHeaderTimeout is the default timeout of reading the PROXY header.
+	DefaultProxyHeaderTimeout = 5 * time.Second
+)
+
+// ProxyProtocolConfig defines the config for the PROXY protocol listener.
+type ProxyProtocolConfig struct {
+	// TrustedCIDRs are the sources allowed to send the PROXY header, the header
+	// from others is not parsed. A cidr can be a single ip.
+	TrustedCIDRs []string
+	// Required rejects the connection of trusted source without the PROXY header.
+	Required bool
+	// ReadTimeout is the timeout of reading the PROXY header.
+	// Optional. Default value DefaultProxyHeaderTimeout.
+	ReadTimeout time.Duration
+}
+
+// ProxyTLV is a Type-Length-Value of PROXY protocol v2.
+type ProxyTLV struct {
+	Type  byte
+	Value []byte
+}
+
+// WithProxyProtocol makes Run, RunTLS and RunListener accept the PROXY protocol,
+// so ctx.RealIp(), ctx.ClientIP() and Logger see the original client address.
+func WithProxyProtocol(config ProxyProtocolConfig) Option {
+	return func(o *options) {
+		o.ProxyProtocol = &config
+	}
+}
+
+// NewProxyProtocolListener wraps l to parse the PROXY protocol v1 and v2 header.
+// It panics on invalid cidr.
+func NewProxyProtocolListener(l net.Listener, config ProxyProtocolConfig) net.Listener {
+	trusted, err := parseCIDRs(config.TrustedCIDRs)
+	if err != nil {
+		panic("invalid PROXY protocol trusted cidr: " + err.Error())
+	}
+
+	pl := &proxyListener{
+		Listener: l,
+		trusted:  trusted,
+		required: config.Required,
+		timeout:  config.ReadTimeout,
+	}
+	if pl.timeout <= 0 {
+		pl.timeout = DefaultProxyHeaderTimeout
+	}
+
+	return pl
+}
+
+type proxyListener struct {
+	net.Listener
+	trusted  []*net.IPNet
+	required bool
+	timeout  time.Duration
+}
+
+// Accept does not read the header, it is read in the goroutine of the connection
+// on the first Read, RemoteAddr or LocalAddr.
+func (l *proxyListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	if !l.isTrusted(c.RemoteAddr()) {
+		return c, nil
+	}
+
+	return &proxyConn{
+		Conn:     c,
+		r:        bufio.NewReader(c),
+		required: l.required,
+		timeout:  l.timeout,
+	}, nil
+}
+
+func (l *proxyListener) isTrusted(addr net.Addr) bool {
+	ta, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+
+	for _, n := range l.trusted {
+		if n.Contains(ta.IP) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// proxyConn is a connection from a trusted source.
+type proxyConn struct {
+	net.Conn
+	r        *bufio.Reader
+	required bool
+	timeout  time.Duration
+
+	once   sync.Once
+	err    error
+	remote net.Addr
+	local  net.Addr
+	tlvs   []ProxyTLV
+
+	mu           sync.Mutex
+	readDeadline time.Time // set by the server, restored after the header is read
+}
+
+func (c *proxyConn) Read(p []byte) (int, error) {
+	c.once.Do(c.readHeader)
+	if c.err != nil {
+		return 0, c.err
+	}
+
+	return c.r.Read(p)
+}
+
+func (c *proxyConn) RemoteAddr() net.Addr {
+	c.once.Do(c.readHeader)
+	if c.remote != nil {
+		return c.remote
+	}
+
+	return c.Conn.RemoteAddr()
+}
+
+func (c *proxyConn) LocalAddr() net.Addr {
+	c.once.Do(c.readHeader)
+	if c.local != nil {
+		return c.local
+	}
+
+	return c.Conn.LocalAddr()
+}
+
+// TLVs returns the TLVs of PROXY protocol v2 header.
+func (c *proxyConn) TLVs() []ProxyTLV {
+	c.once.Do(c.readHeader)
+	return c.tlvs
+}
+
+func (c *proxyConn) SetDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.readDeadline = t
+	return c.Conn.SetDeadline(t)
+}
+
+func (c *proxyConn) SetReadDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.readDeadline = t
+	return c.Conn.SetReadDeadline(t)
+}
+
+// readHeader reads the header within timeout, or the earlier read deadline
+// of the server, which is restored after it.
+func (c *proxyConn) readHeader() {
+	c.mu.Lock()
+	d := time.Now().Add(c.timeout)
+	if !c.readDeadline.IsZero() && c.readDeadline.Before(d) {
+		d = c.readDeadline
+	}
+	c.Conn.SetReadDeadline(d)
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		c.Conn.SetReadDeadline(c.readDeadline)
+		c.mu.Unlock()
+	}()
+
+	if c.err = c.parse(); c.err != nil {
+		c.Conn.Close()
+	}
+}
+
+func (c *proxyConn) parse() error {
+	b, err := c.r.Peek(len(proxyV1Prefix))
+	if err != nil && !(err == io.EOF && !c.required) {
+		return err
+	}
+
+	switch {
+	case bytes.Equal(b, proxyV1Prefix):
+		return c.parseV1()
+	case bytes.Equal(b, proxyV2Sig[:len(proxyV1Prefix)]):
+		if b, err = c.r.Peek(len(proxyV2Sig)); err != nil {
+			return err
+		}
+		if bytes.Equal(b, proxyV2Sig) {
+			return c.parseV2()
+		}
+	}
+
+	if c.required {
+		return ErrProxyHeader
+	}
+
+	return nil
+}
+
+// parseV1 parses e.g. "PROXY TCP4 192.0.2.1 192.0.2.2 56324 443\r\n".
+func (c *proxyConn) parseV1() error {
+	var line []byte
+	for {
+		b, err := c.r.ReadByte()
+		if err != nil {
+			return err
+		}
+		line = append(line, b)
+
+		if b == '\n' {
+			break
+		}
+		if len(line) >= proxyV1MaxLen {
+			return ErrProxyHeader
+		}
+	}
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return ErrProxyHeader
+	}
+
+	fields := strings.Split(string(line[:len(line)-2]), " ")
+	if len(fields) < 2 {
+		return ErrProxyHeader
+	}
+	if fields[1] == "UNKNOWN" {
+		return nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return ErrProxyHeader
+	}
+
+	src, err := parseProxyAddr(fields[1], fields[2], fields[4])
+	if err != nil {
+		return err
+	}
+	dst, err := parseProxyAddr(fields[1], fields[3], fields[5])
+	if err != nil {
+		return err
+	}
+	c.remote, c.local = src, dst
+
+	return nil
+}
+
+// parseProxyAddr parses the address of v1, ip must match family TCP4 or TCP6.
+func parseProxyAddr(family, ip, port string) (*net.TCPAddr, error) {
+	a := &net.TCPAddr{IP: net.ParseIP(ip)}
+	if a.IP == nil || (family == "TCP4") == strings.Contains(ip, ":") {
+		return nil, fmt.Errorf("%w: ip %q of %s", ErrProxyHeader, ip, family)
+	}
+
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("%w: port %q", ErrProxyHeader, port)
+	}
+	a.Port = int(p)
+
+	return a, nil
+}
+
+// parseV2 parses the binary header: signature(12) ver_cmd(1) fam(1) len(2) addresses TLVs.
+func (c *proxyConn) parseV2() error {
+	hdr := make([]byte, 16)
+	if _, err := io.ReadFull(c.r, hdr); err != nil {
+		return err
+	}
+	if hdr[12]>>4 != 2 {
+		return fmt.Errorf("%w: version %d", ErrProxyHeader, hdr[12]>>4)
+	}
+
+	data := make([]byte, binary.BigEndian.Uint16(hdr[14:]))
+	if _, err := io.ReadFull(c.r, data); err != nil {
+		return err
+	}
+
+	switch hdr[12] & 0x0F {
+	case 0x0: // LOCAL, e.g. health check of the proxy
+		return nil
+	case 0x1: // PROXY
+	default:
+		return fmt.Errorf("%w: command %d", ErrProxyHeader, hdr[12]&0x0F)
+	}
+
+	switch hdr[13] & 0x0F {
+	case 0x0: // UNSPEC, keep the addresses of connection like LOCAL
+		return nil
+	case 0x1: // STREAM
+	default:
+		return fmt.Errorf("%w: transport %d", ErrProxyHeader, hdr[13]&0x0F)
+	}
+
+	var n int
+	switch hdr[13] >> 4 {
+	case 0x1: // AF_INET
+		n = 12
+		if len(data) < n {
+			return ErrProxyHeader
+		}
+		c.remote = &net.TCPAddr{IP: net.IP(data[0:4]), Port: int(binary.BigEndian.Uint16(data[8:]))}
+		c.local = &net.TCPAddr{IP: net.IP(data[4:8]), Port: int(binary.BigEndian.Uint16(data[10:]))}
+	case 0x2: // AF_INET6
+		n = 36
+		if len(data) < n {
+			return ErrProxyHeader
+		}
+		c.remote = &net.TCPAddr{IP: net.IP(data[0:16]), Port: int(binary.BigEndian.Uint16(data[32:]))}
+		c.local = &net.TCPAddr{IP: net.IP(data[16:32]), Port: int(binary.BigEndian.Uint16(data[34:]))}
+	case 0x3: // AF_UNIX, keep the addresses of connection
+		n = 216
+		if len(data) < n {
+			return ErrProxyHeader
+		}
+	}
+
+	tlvs, err := parseProxyTLVs(data[n:])
+	if err != nil {
+		return err
+	}
+	c.tlvs = tlvs
+
+	return nil
+}
+
+func parseProxyTLVs(data []byte) ([]ProxyTLV, error) {
+	var tlvs []ProxyTLV
+
+	for len(data) > 0 {
+		if len(data) < 3 {
+			return nil, ErrProxyHeader
+		}
+
+		n := int(binary.BigEndian.Uint16(data[1:]))
+		if len(data) < 3+n {
+			return nil, ErrProxyHeader
+		}
+
+		tlvs = append(tlvs, ProxyTLV{Type: data[0], Value: data[3 : 3+n]})
+		data = data[3+n:]
+	}
+
+	return tlvs, nil
+}
+
+type proxyConnKey struct{}
+
+// proxyConnContext is http.Server.ConnContext to find the TLVs of request.
+func proxyConnContext(ctx context.Context, c net.Conn) context.Context {
+	if tc, ok := c.(*tls.Conn); ok {
+		c = tc.NetConn()
+	}
+	if pc, ok := c.(*proxyConn); ok {
+		return context.WithValue(ctx, proxyConnKey{}, pc)
+	}
+
+	return ctx
+}
+
+// ProxyTLVs returns the TLVs of PROXY protocol v2 header, nil if there is none.
+func (ctx *Context) ProxyTLVs() []ProxyTLV {
+	if pc, ok := ctx.Request.Context().Value(proxyConnKey{}).(*proxyConn); ok {
+		return pc.TLVs()
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package water
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func proxyV2Header(tlvs ...ProxyTLV) []byte {
+	data := []byte{
+		203, 0, 113, 7, // src
+		192, 0, 2, 1, // dst
+		0xDC, 0x04, // src port 56324
+		0x01, 0xBB, // dst port 443
+	}
+	for _, t := range tlvs {
+		data = append(data, t.Type, 0, 0)
+		binary.BigEndian.PutUint16(data[len(data)-2:], uint16(len(t.Value)))
+		data = append(data, t.Value...)
+	}
+
+	b := append([]byte{}, proxyV2Sig...)
+	b = append(b, 0x21, 0x11, 0, 0) // v2 PROXY, TCP over IPv4
+	binary.BigEndian.PutUint16(b[14:], uint16(len(data)))
+
+	return append(b, data...)
+}
+
+func TestProxyProtocol(t *testing.T) {
+	r := NewRouter()
+	r.GET("/", func(ctx *Context) {
+		s := ctx.RealIp() + " " + ctx.ClientIP()
+		for _, t := range ctx.ProxyTLVs() {
+			s += " " + string(t.Value)
+		}
+		ctx.String(200, s)
+	})
+
+	run := func(config ProxyProtocolConfig) (string, func()) {
+		e := r.Handler(WithProxyProtocol(config))
+
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		So(err, ShouldBeNil)
+		go e.RunListener(l)
+
+		return l.Addr().String(), func() { l.Close() }
+	}
+
+	request := func(addr string, header []byte) (*http.Response, error) {
+		c, err := net.Dial("tcp", addr)
+		if err != nil {
+			return nil, err
+		}
+		defer c.Close()
+		c.SetDeadline(time.Now().Add(5 * time.Second))
+
+		c.Write(header)
+		io.WriteString(c, "GET / HTTP/1.1\r\nHost: example.com\r\nConnection: close\r\n\r\n")
+
+		return http.ReadResponse(bufio.NewReader(c), nil)
+	}
+
+	body := func(resp *http.Response) string {
+		b, _ := io.ReadAll(resp.Body)
+		return string(b)
+	}
+
+	Convey("v1", t, func() {
+		addr, stop := run(ProxyProtocolConfig{TrustedCIDRs: []string{"127.0.0.1"}})
+		defer stop()
+
+		resp, err := request(addr, []byte("PROXY TCP4 203.0.113.7 192.0.2.1 56324 443\r\n"))
+		So(err, ShouldBeNil)
+		So(body(resp), ShouldEqual, "203.0.113.7 203.0.113.7")
+
+		resp, err = request(addr, []byte("PROXY TCP6 2001:db8::7 2001:db8::1 56324 443\r\n"))
+		So(err, ShouldBeNil)
+		So(body(resp), ShouldEqual, "2001:db8::7 2001:db8::7")
+
+		// the header is optional
+		resp, err = request(addr, nil)
+		So(err, ShouldBeNil)
+		So(body(resp), ShouldEqual, "127.0.0.1 127.0.0.1")
+
+		_, err = request(addr, []byte("PROXY TCP4 203.0.113.7\r\n"))
+		So(err, ShouldNotBeNil)
+
+		// the family does not match the address
+		_, err = request(addr, []byte("PROXY TCP4 2001:db8::7 2001:db8::1 56324 443\r\n"))
+		So(err, ShouldNotBeNil)
+		_, err = request(addr, []byte("PROXY TCP6 203.0.113.7 192.0.2.1 56324 443\r\n"))
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("v2", t, func() {
+		addr, stop := run(ProxyProtocolConfig{TrustedCIDRs: []string{"127.0.0.0/8"}, Required: true})
+		defer stop()
+
+		resp, err := request(addr, proxyV2Header(ProxyTLV{Type: 0x04, Value: []byte("vpce-1")}))
+		So(err, ShouldBeNil)
+		So(body(resp), ShouldEqual, "203.0.113.7 203.0.113.7 vpce-1")
+
+		_, err = request(addr, nil)
+		So(err, ShouldNotBeNil)
+
+		// UDP is rejected
+		h := proxyV2Header()
+		h[13] = 0x12
+		_, err = request(addr, h)
+		So(err, ShouldNotBeNil)
+
+		// unspecified transport keeps the address of connection
+		h[13] = 0x10
+		resp, err = request(addr, h)
+		So(err, ShouldBeNil)
+		So(body(resp), ShouldEqual, "127.0.0.1 127.0.0.1")
+	})
+
+	Convey("read deadline of server is kept", t, func() {
+		client, server := net.Pipe()
+		defer client.Close()
+
+		c := &proxyConn{Conn: server, r: bufio.NewReader(server), timeout: time.Second}
+		c.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+
+		go client.Write([]byte("PROXY TCP4 203.0.113.7 192.0.2.1 56324 443\r\n"))
+		So(c.RemoteAddr().String(), ShouldEqual, "203.0.113.7:56324")
+
+		// no more data, Read times out by the deadline of server instead of blocking
+		_, err := c.Read(make([]byte, 1))
+		So(errors.Is(err, os.ErrDeadlineExceeded), ShouldBeTrue)
+	})
+
+	Convey("untrusted source", t, func() {
+		addr, stop := run(ProxyProtocolConfig{TrustedCIDRs: []string{"10.0.0.0/8"}})
+		defer stop()
+
+		// the header is not parsed, so it is a bad request
+		resp, err := request(addr, []byte("PROXY TCP4 203.0.113.7 192.0.2.1 56324 443\r\n"))
+		So(err, ShouldBeNil)
+		So(resp.StatusCode, ShouldEqual, http.StatusBadRequest)
+	})
+
+	Convey("parseProxyTLVs", t, func() {
+		_, err := parseProxyTLVs([]byte{1, 0, 5, 'a'})
+		So(err, ShouldEqual, ErrProxyHeader)
+	})
+}
@@ -0,0 +1,114 @@
+package water
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTrustedProxies(t *testing.T) {
+	// serveFrom serves the request from remoteAddr
+	serveFrom := func(remoteAddr string, header http.Header, opts ...Option) string {
+		e := newTestEngine("GET", "/", func(ctx *Context) {
+			ctx.String(200, ctx.ClientIP()+" "+ctx.BaseURL())
+		}, opts...)
+
+		req := newTestRequest("GET", "http://example.com/", "")
+		req.RemoteAddr = remoteAddr
+		for k, vs := range header {
+			req.Header[k] = vs
+		}
+
+		return serveRequest(e, req).Body.String()
+	}
+
+	trusted := WithTrustedProxies("10.0.0.0/8", "2001:db8::1")
+
+	Convey("untrusted remote", t, func() {
+		h := http.Header{
+			"X-Forwarded-For":   {"1.1.1.1"},
+			"X-Forwarded-Proto": {"https"},
+			"X-Real-Ip":         {"1.1.1.1"},
+			"Forwarded":         {"for=1.1.1.1;proto=https"},
+		}
+		So(serveFrom("2.2.2.2:1234", h), ShouldEqual, "2.2.2.2 http://example.com")
+		So(serveFrom("2.2.2.2:1234", h, trusted), ShouldEqual, "2.2.2.2 http://example.com")
+	})
+
+	Convey("X-Forwarded-For", t, func() {
+		h := http.Header{
+			"X-Forwarded-For":   {"9.9.9.9, 1.1.1.1", "10.0.0.2"},
+			"X-Forwarded-Proto": {"https"},
+			"X-Forwarded-Host":  {"api.example.com"},
+		}
+		// 9.9.9.9 is spoofed by 1.1.1.1
+		So(serveFrom("10.0.0.1:1234", h, trusted), ShouldEqual, "1.1.1.1 https://api.example.com")
+
+		// the leftmost proto and host are spoofed by the client
+		h = http.Header{
+			"X-Forwarded-For":   {"1.1.1.1"},
+			"X-Forwarded-Proto": {"https, http"},
+			"X-Forwarded-Host":  {"evil.example.com", "api.example.com"},
+		}
+		So(serveFrom("10.0.0.1:1234", h, trusted), ShouldEqual, "1.1.1.1 http://api.example.com")
+
+		// paired with the hops
+		h = http.Header{
+			"X-Forwarded-For":   {"9.9.9.9, 1.1.1.1, 10.0.0.2"},
+			"X-Forwarded-Proto": {"http, https, http"},
+			"X-Forwarded-Host":  {"evil.example.com, api.example.com, internal"},
+		}
+		So(serveFrom("10.0.0.1:1234", h, trusted), ShouldEqual, "1.1.1.1 https://api.example.com")
+
+		h = http.Header{"X-Forwarded-For": {"10.0.0.3, 10.0.0.2"}}
+		So(serveFrom("10.0.0.1:1234", h, trusted), ShouldEqual, "10.0.0.3 http://example.com")
+
+		h = http.Header{"X-Real-Ip": {"1.1.1.1"}}
+		So(serveFrom("10.0.0.1:1234", h, trusted), ShouldEqual, "1.1.1.1 http://example.com")
+	})
+
+	Convey("Forwarded", t, func() {
+		h := http.Header{
+			"Forwarded":       {`for=9.9.9.9, for="[2001:db8:cafe::17]:4711";proto=https;host="a.example.com", for=10.0.0.2:80;proto=http`},
+			"X-Forwarded-For": {"3.3.3.3"},
+		}
+		So(serveFrom("[2001:db8::1]:1234", h, trusted), ShouldEqual, "2001:db8:cafe::17 https://a.example.com")
+
+		h = http.Header{"Forwarded": {"for=unknown, for=10.0.0.2"}}
+		So(serveFrom("10.0.0.1:1234", h, trusted), ShouldEqual, "10.0.0.2 http://example.com")
+
+		h = http.Header{"Forwarded": {"for=_hidden;proto=https"}}
+		So(serveFrom("10.0.0.1:1234", h, trusted), ShouldEqual, "10.0.0.1 http://example.com")
+	})
+
+	Convey("parseForwarded", t, func() {
+		hops := parseForwarded(`For="[2001:db8::1]";Proto=HTTPS;by=x, for=192.0.2.43;host="a\"b"`)
+		So(hops, ShouldResemble, []forwardedHop{
+			{ip: "[2001:db8::1]", scheme: "https"},
+			{ip: "192.0.2.43", host: `a"b`},
+		})
+	})
+
+	Convey("per engine", t, func() {
+		r := NewRouter()
+		r.GET("/", func(ctx *Context) {
+			ctx.String(200, ctx.ClientIP())
+		})
+		public := r.Handler()
+		admin := r.Handler(trusted)
+
+		for _, c := range []struct {
+			e    *Engine
+			want string
+		}{{public, "10.0.0.1"}, {admin, "1.1.1.1"}} {
+			req := newTestRequest("GET", "http://example.com/", "", HeaderXForwardedFor, "1.1.1.1")
+			req.RemoteAddr = "10.0.0.1:1234"
+			So(serveRequest(c.e, req).Body.String(), ShouldEqual, c.want)
+		}
+	})
+
+	Convey("invalid cidr", t, func() {
+		So(func() { WithTrustedProxies("10.0.0.0/33") }, ShouldPanic)
+	})
+}
@@ -1,5 +1,13 @@
 package water
 
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+
+	"github.com/meilihao/logx"
+)
+
 const (
 	DEFAULT_TPL_SET_NAME = ""
 )
@@ -13,8 +21,8 @@ func SetRender(r Render) {
 type defaultRender struct{}
 
 func (r *defaultRender) HTMLSet(ctx *Context, code int, setName, tplName string, data interface{}) {
-	ctx.WriteHeader(code)
 	ctx.Header().Set(HeaderContentType, MIMETextHTMLCharsetUTF8)
+	ctx.WriteHeader(code)
 }
 
 func init() {
@@ -38,3 +46,88 @@ func (ctx *Context) HTMLSet(code int, setName, tplName string, data interface{})
 func (ctx *Context) renderHTML(code int, setName, tplName string, data interface{}) {
 	_render.HTMLSet(ctx, code, setName, tplName, data)
 }
+
+// Renderer renders the response body for ctx.Render.
+type Renderer interface {
+	// ContentType returns the Content-Type of the body, "" means detecting by the body
+	ContentType() string
+	Render(io.Writer) error
+}
+
+// Render writes the headers of r and the status line, then the body by r.
+// The body is dropped for HEAD requests and the status which forbids a body(1xx, 204 and 304).
+// All the data renderers like ctx.JSON() use it.
+func (ctx *Context) Render(code int, r Renderer) error {
+	if !bodyAllowedForStatus(code) {
+		ctx.WriteHeader(code)
+		return nil
+	}
+
+	if ct := r.ContentType(); ct != "" {
+		ctx.Header().Set(HeaderContentType, ct)
+	}
+	ctx.WriteHeader(code)
+
+	if ctx.Request.Method == http.MethodHead {
+		return nil
+	}
+
+	err := r.Render(ctx)
+	if err != nil {
+		logx.Warn(err)
+	}
+	return err
+}
+
+// from net/http
+func bodyAllowedForStatus(code int) bool {
+	switch {
+	case code >= 100 && code <= 199:
+		return false
+	case code == http.StatusNoContent:
+		return false
+	case code == http.StatusNotModified:
+		return false
+	}
+	return true
+}
+
+type dataRender struct {
+	contentType string
+	data        []byte
+}
+
+func (r dataRender) ContentType() string {
+	return r.contentType
+}
+
+func (r dataRender) Render(w io.Writer) error {
+	_, err := w.Write(r.data)
+	return err
+}
+
+type stringRender struct {
+	contentType string
+	data        string
+}
+
+func (r stringRender) ContentType() string {
+	return r.contentType
+}
+
+func (r stringRender) Render(w io.Writer) error {
+	_, err := io.WriteString(w, r.data)
+	return err
+}
+
+type xmlRender struct {
+	v interface{}
+}
+
+func (xmlRender) ContentType() string {
+	return MIMEApplicationXMLCharsetUTF8
+}
+
+func (r xmlRender) Render(w io.Writer) error {
+	return xml.NewEncoder(w).Encode(r.v)
+}
@@ -0,0 +1,192 @@
+package water
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"unicode/utf8"
+)
+
+var (
+	// SecureJSONPrefix is written before the JSON array by ctx.SecureJSON
+	// to prevent json hijacking.
+	SecureJSONPrefix = "while(1);"
+
+	// ErrInvalidJSONPCallback is returned by ctx.JSONP when the callback is not a js identifier
+	ErrInvalidJSONPCallback = errors.New("water: invalid jsonp callback")
+
+	// allow "cb", "$.cb", "jQuery123_456.cb", not allow "alert(1);cb"
+	jsonpCallbackRegexp = regexp.MustCompile(`^[a-zA-Z_$][0-9a-zA-Z_$]*(\.[a-zA-Z_$][0-9a-zA-Z_$]*)*$`)
+)
+
+const maxJSONPCallbackLength = 128
+
+// IndentedJSON renders v as pretty-printed JSON, recommended for development only.
+func (ctx *Context) IndentedJSON(code int, v interface{}) error {
+	return ctx.Render(code, indentedJSONRender{v})
+}
+
+// SecureJSON renders v as JSON, and writes SecureJSONPrefix before it if v is a JSON array.
+func (ctx *Context) SecureJSON(code int, v interface{}) error {
+	return ctx.Render(code, secureJSONRender{SecureJSONPrefix, v})
+}
+
+// JSONP renders v as JSONP with the callback from query "callback",
+// it is the same as ctx.JSON if no callback.
+// Reply 400 if the callback is not a js identifier like "cb" or "$.cb".
+func (ctx *Context) JSONP(code int, v interface{}) error {
+	callback := ctx.Request.URL.Query().Get("callback")
+	if callback == "" {
+		return ctx.JSON(code, v)
+	}
+
+	if len(callback) > maxJSONPCallbackLength || !jsonpCallbackRegexp.MatchString(callback) {
+		ctx.Abort(http.StatusBadRequest)
+		return ErrInvalidJSONPCallback
+	}
+
+	return ctx.Render(code, jsonpRender{callback, v})
+}
+
+// AsciiJSON renders v as JSON, and escapes all non-ASCII characters to "\uXXXX".
+func (ctx *Context) AsciiJSON(code int, v interface{}) error {
+	return ctx.Render(code, asciiJSONRender{v})
+}
+
+// PureJSON renders v as JSON, HTML characters are not escaped.
+func (ctx *Context) PureJSON(code int, v interface{}) error {
+	return ctx.Render(code, pureJSONRender{v})
+}
+
+type jsonRender struct {
+	v interface{}
+}
+
+func (jsonRender) ContentType() string {
+	return MIMEApplicationJSONCharsetUTF8
+}
+
+func (r jsonRender) Render(w io.Writer) error {
+	return json.NewEncoder(w).Encode(r.v)
+}
+
+type indentedJSONRender struct {
+	v interface{}
+}
+
+func (indentedJSONRender) ContentType() string {
+	return MIMEApplicationJSONCharsetUTF8
+}
+
+func (r indentedJSONRender) Render(w io.Writer) error {
+	data, err := json.MarshalIndent(r.v, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+type secureJSONRender struct {
+	prefix string
+	v      interface{}
+}
+
+func (secureJSONRender) ContentType() string {
+	return MIMEApplicationJSONCharsetUTF8
+}
+
+func (r secureJSONRender) Render(w io.Writer) error {
+	data, err := json.Marshal(r.v)
+	if err != nil {
+		return err
+	}
+
+	if bytes.HasPrefix(data, []byte("[")) {
+		if _, err = io.WriteString(w, r.prefix); err != nil {
+			return err
+		}
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+type jsonpRender struct {
+	callback string
+	v        interface{}
+}
+
+func (jsonpRender) ContentType() string {
+	return MIMEApplicationJavaScriptUTF8
+}
+
+func (r jsonpRender) Render(w io.Writer) error {
+	data, err := json.Marshal(r.v)
+	if err != nil {
+		return err
+	}
+
+	// "/**/" prevents the Rosetta Flash attack
+	_, err = fmt.Fprintf(w, "/**/%s(%s);", r.callback, data)
+	return err
+}
+
+type asciiJSONRender struct {
+	v interface{}
+}
+
+func (asciiJSONRender) ContentType() string {
+	return MIMEApplicationJSONCharsetUTF8
+}
+
+func (r asciiJSONRender) Render(w io.Writer) error {
+	data, err := json.Marshal(r.v)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(asciiJSON(data))
+	return err
+}
+
+type pureJSONRender struct {
+	v interface{}
+}
+
+func (pureJSONRender) ContentType() string {
+	return MIMEApplicationJSONCharsetUTF8
+}
+
+func (r pureJSONRender) Render(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+
+	return enc.Encode(r.v)
+}
+
+// asciiJSON escapes non-ASCII characters of data to "\uXXXX", use surrogate pair for the rune > 0xFFFF.
+func asciiJSON(data []byte) []byte {
+	buf := bytes.NewBuffer(make([]byte, 0, len(data)))
+
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		data = data[size:]
+
+		switch {
+		case r < utf8.RuneSelf:
+			buf.WriteByte(byte(r))
+		case r > 0xFFFF:
+			r -= 0x10000
+			fmt.Fprintf(buf, `\u%04x\u%04x`, 0xD800+(r>>10), 0xDC00+(r&0x3FF))
+		default:
+			fmt.Fprintf(buf, `\u%04x`, r)
+		}
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,30 @@
+//go:build !nomsgpack
+// +build !nomsgpack
+
+package water
+
+import (
+	"io"
+
+	"github.com/ugorji/go/codec"
+)
+
+// go build -tags nomsgpack, to remove the MsgPack renderer and its dependency
+var msgpackHandle codec.MsgpackHandle
+
+// MsgPack renders v as MessagePack.
+func (ctx *Context) MsgPack(code int, v interface{}) error {
+	return ctx.Render(code, msgpackRender{v})
+}
+
+type msgpackRender struct {
+	v interface{}
+}
+
+func (msgpackRender) ContentType() string {
+	return MIMEApplicationMsgPack
+}
+
+func (r msgpackRender) Render(w io.Writer) error {
+	return codec.NewEncoder(w, &msgpackHandle).Encode(r.v)
+}
@@ -0,0 +1,23 @@
+//go:build !nomsgpack
+// +build !nomsgpack
+
+package water
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/ugorji/go/codec"
+)
+
+func TestRenderMsgPack(t *testing.T) {
+	Convey("MsgPack", t, func() {
+		resp := serveRender(func(ctx *Context) { ctx.MsgPack(200, H{"a": 1}) }, "/")
+		So(resp.Header().Get(HeaderContentType), ShouldEqual, MIMEApplicationMsgPack)
+
+		var v map[string]int
+		err := codec.NewDecoderBytes(resp.Body.Bytes(), &msgpackHandle).Decode(&v)
+		So(err, ShouldBeNil)
+		So(v["a"], ShouldEqual, 1)
+	})
+}
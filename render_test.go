@@ -0,0 +1,122 @@
+package water
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func serveRender(h func(ctx *Context), url string) *httptest.ResponseRecorder {
+	return serve(newTestEngine("GET", "/", h), "GET", url, "")
+}
+
+func TestRenderJSON(t *testing.T) {
+	data := H{"html": "<b>", "name": "水"}
+
+	Convey("JSON", t, func() {
+		resp := serveRender(func(ctx *Context) { ctx.JSON(200, data) }, "/")
+		So(resp.Header().Get(HeaderContentType), ShouldEqual, MIMEApplicationJSONCharsetUTF8)
+		So(resp.Body.String(), ShouldEqual, `{"html":"\u003cb\u003e","name":"水"}`+"\n")
+	})
+
+	Convey("IndentedJSON", t, func() {
+		resp := serveRender(func(ctx *Context) { ctx.IndentedJSON(200, H{"a": 1}) }, "/")
+		So(resp.Body.String(), ShouldEqual, "{\n    \"a\": 1\n}")
+	})
+
+	Convey("SecureJSON", t, func() {
+		resp := serveRender(func(ctx *Context) { ctx.SecureJSON(200, []int{1, 2}) }, "/")
+		So(resp.Body.String(), ShouldEqual, "while(1);[1,2]")
+
+		resp = serveRender(func(ctx *Context) { ctx.SecureJSON(200, H{"a": 1}) }, "/")
+		So(resp.Body.String(), ShouldEqual, `{"a":1}`)
+	})
+
+	Convey("JSONP", t, func() {
+		resp := serveRender(func(ctx *Context) { ctx.JSONP(200, H{"a": 1}) }, "/?callback=jQuery_1.cb")
+		So(resp.Header().Get(HeaderContentType), ShouldEqual, MIMEApplicationJavaScriptUTF8)
+		So(resp.Body.String(), ShouldEqual, `/**/jQuery_1.cb({"a":1});`)
+
+		resp = serveRender(func(ctx *Context) { ctx.JSONP(200, H{"a": 1}) }, "/?callback=alert%281%29%3Bcb")
+		So(resp.Code, ShouldEqual, http.StatusBadRequest)
+
+		resp = serveRender(func(ctx *Context) { ctx.JSONP(200, H{"a": 1}) }, "/")
+		So(resp.Header().Get(HeaderContentType), ShouldEqual, MIMEApplicationJSONCharsetUTF8)
+	})
+
+	Convey("AsciiJSON", t, func() {
+		resp := serveRender(func(ctx *Context) { ctx.AsciiJSON(200, H{"name": "水😀"}) }, "/")
+		So(resp.Body.String(), ShouldEqual, `{"name":"\u6c34\ud83d\ude00"}`)
+	})
+
+	Convey("PureJSON", t, func() {
+		resp := serveRender(func(ctx *Context) { ctx.PureJSON(200, data) }, "/")
+		So(resp.Body.String(), ShouldEqual, `{"html":"<b>","name":"水"}`+"\n")
+	})
+}
+
+func TestRenderYAML(t *testing.T) {
+	Convey("YAML", t, func() {
+		resp := serveRender(func(ctx *Context) { ctx.YAML(200, H{"a": 1}) }, "/")
+		So(resp.Header().Get(HeaderContentType), ShouldEqual, MIMEApplicationYAMLCharsetUTF8)
+		So(resp.Body.String(), ShouldEqual, "a: 1\n")
+	})
+}
+
+type csvRender struct {
+	rows [][]string
+}
+
+func (csvRender) ContentType() string {
+	return "text/csv"
+}
+
+func (r csvRender) Render(w io.Writer) error {
+	for _, row := range r.rows {
+		if _, err := io.WriteString(w, strings.Join(row, ",")+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestRender(t *testing.T) {
+	Convey("Content-Type is set before the status line", t, func() {
+		resp := serveRender(func(ctx *Context) { ctx.String(http.StatusCreated, "a") }, "/")
+		So(resp.Code, ShouldEqual, http.StatusCreated)
+		So(resp.Header().Get(HeaderContentType), ShouldEqual, MIMETextPlainCharsetUTF8)
+
+		resp = serveRender(func(ctx *Context) { ctx.Data(200, "image/png", []byte{1}) }, "/")
+		So(resp.Header().Get(HeaderContentType), ShouldEqual, "image/png")
+
+		resp = serveRender(func(ctx *Context) { ctx.HTMLRaw(200, "<b>a</b>") }, "/")
+		So(resp.Header().Get(HeaderContentType), ShouldEqual, MIMETextHTMLCharsetUTF8)
+	})
+
+	Convey("no body for HEAD, 204 and 304", t, func() {
+		r := NewRouter()
+		r.HEAD("/", func(ctx *Context) { ctx.JSON(200, H{"a": 1}) })
+		r.GET("/204", func(ctx *Context) { ctx.JSON(http.StatusNoContent, H{"a": 1}) })
+		r.GET("/304", func(ctx *Context) { ctx.String(http.StatusNotModified, "a") })
+		e := r.Handler()
+
+		for _, v := range [][2]string{{"HEAD", "/"}, {"GET", "/204"}, {"GET", "/304"}} {
+			resp := httptest.NewRecorder()
+			req, _ := http.NewRequest(v[0], v[1], nil)
+			e.ServeHTTP(resp, req)
+			So(resp.Body.Len(), ShouldEqual, 0)
+		}
+	})
+
+	Convey("user-defined Renderer", t, func() {
+		resp := serveRender(func(ctx *Context) {
+			ctx.Render(200, csvRender{[][]string{{"a", "b"}, {"1", "2"}}})
+		}, "/")
+		So(resp.Header().Get(HeaderContentType), ShouldEqual, "text/csv")
+		So(resp.Body.String(), ShouldEqual, "a,b\n1,2\n")
+	})
+}
@@ -0,0 +1,29 @@
+package water
+
+import (
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAML renders v as YAML.
+func (ctx *Context) YAML(code int, v interface{}) error {
+	return ctx.Render(code, yamlRender{v})
+}
+
+type yamlRender struct {
+	v interface{}
+}
+
+func (yamlRender) ContentType() string {
+	return MIMEApplicationYAMLCharsetUTF8
+}
+
+func (r yamlRender) Render(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	if err := enc.Encode(r.v); err != nil {
+		return err
+	}
+
+	return enc.Close()
+}
@@ -0,0 +1,125 @@
+package water
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/meilihao/logx"
+)
+
+// responseWriter wraps the http.ResponseWriter of a request to record
+// the status, the bytes written and the time of the status line.
+// It is pooled with Context, and satisfies ResponseWriter with or without
+// the "extended" tag: Flush, Hijack and Push are forwarded when
+// the wrapped writer supports them, otherwise http.ErrNotSupported.
+type responseWriter struct {
+	http.ResponseWriter
+
+	written bool
+	status  int
+	size    int
+	writeAt time.Time
+
+	beforeWrite []func()
+}
+
+var (
+	_ ResponseWriter = &responseWriter{}
+	_ http.Pusher    = &responseWriter{}
+)
+
+func (w *responseWriter) reset(rw http.ResponseWriter) {
+	w.ResponseWriter = rw
+	w.written = false
+	w.status = 0
+	w.size = 0
+	w.writeAt = time.Time{}
+	w.beforeWrite = w.beforeWrite[:0]
+}
+
+// WriteHeader runs the OnBeforeWrite hooks, then writes the status line.
+func (w *responseWriter) WriteHeader(code int) {
+	if w.written {
+		logx.Warn("water: multiple ctx.WriteHeader calls")
+		return
+	}
+
+	// hooks may write header but not the status line again
+	hooks := w.beforeWrite
+	w.beforeWrite = nil
+	for _, f := range hooks {
+		f()
+	}
+	w.beforeWrite = hooks[:0]
+
+	w.written = true
+	w.status = code
+	w.writeAt = time.Now()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write writes 200 before data if no status line, and detects the Content-Type if not set.
+func (w *responseWriter) Write(data []byte) (int, error) {
+	if !w.written {
+		header := w.Header()
+		if len(data) > 0 && header.Get(HeaderContentType) == "" {
+			header.Set(HeaderContentType, http.DetectContentType(data))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+
+	n, err := w.ResponseWriter.Write(data)
+	w.size += n
+	return n, err
+}
+
+// Flush writes 200 if no status line, then flushes the buffered data to client.
+func (w *responseWriter) Flush() {
+	w.FlushError()
+}
+
+// FlushError is the same as Flush but reports http.ErrNotSupported, used by http.ResponseController.
+func (w *responseWriter) FlushError() error {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	return http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack lets the caller take over the connection, the response is
+// treated as written after that.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, brw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil {
+		w.written = true
+	}
+
+	return conn, brw, err
+}
+
+// Push implements http.Pusher for HTTP/2 server push.
+func (w *responseWriter) Push(target string, opts *http.PushOptions) error {
+	if p, ok := w.ResponseWriter.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+
+	return http.ErrNotSupported
+}
+
+// CloseNotify implements http.CloseNotifier for the "extended" tag.
+// Deprecated: please use ctx.Request.Context().Done().
+func (w *responseWriter) CloseNotify() <-chan bool {
+	if cn, ok := w.ResponseWriter.(http.CloseNotifier); ok {
+		return cn.CloseNotify()
+	}
+
+	return make(chan bool)
+}
+
+// Unwrap returns the wrapped http.ResponseWriter, used by http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,67 @@
+package water
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestResponseWriter(t *testing.T) {
+	Convey("track status and size", t, func() {
+		var ctx *Context
+		resp := serveRender(func(c *Context) {
+			ctx = c
+			c.ResponseWriter.Write([]byte("hello"))
+			c.ResponseWriter.Write([]byte(" water"))
+		}, "/")
+
+		So(resp.Code, ShouldEqual, http.StatusOK)
+		So(ctx.Written(), ShouldBeTrue)
+		So(ctx.Status(), ShouldEqual, http.StatusOK)
+		So(ctx.Size(), ShouldEqual, len("hello water"))
+		So(ctx.WrittenAt().IsZero(), ShouldBeFalse)
+	})
+
+	Convey("OnBeforeWrite", t, func() {
+		r := NewRouter()
+		r.Use(func(ctx *Context) {
+			ctx.OnBeforeWrite(func() {
+				ctx.Header().Set("X-Status-Before", "1")
+			})
+			ctx.Next()
+		})
+		r.GET("/", func(ctx *Context) {
+			ctx.OnBeforeWrite(func() {
+				ctx.Header().Set("X-Status-Before", ctx.Header().Get("X-Status-Before")+"2")
+			})
+			ctx.String(http.StatusTeapot, "tea")
+		})
+		e := r.Handler()
+
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/", nil)
+		e.ServeHTTP(resp, req)
+		So(resp.Code, ShouldEqual, http.StatusTeapot)
+		So(resp.Header().Get("X-Status-Before"), ShouldEqual, "12")
+	})
+
+	Convey("Flush, Hijack, Push and http.ResponseController", t, func() {
+		resp := serveRender(func(ctx *Context) {
+			rc := http.NewResponseController(ctx.ResponseWriter)
+			So(rc.Flush(), ShouldBeNil)
+			So(ctx.Status(), ShouldEqual, http.StatusOK)
+
+			So(errors.Is(rc.EnableFullDuplex(), http.ErrNotSupported), ShouldBeTrue)
+
+			_, _, err := rc.Hijack()
+			So(errors.Is(err, http.ErrNotSupported), ShouldBeTrue)
+
+			So(ctx.ResponseWriter.(http.Pusher).Push("/a.js", nil), ShouldEqual, http.ErrNotSupported)
+		}, "/")
+
+		So(resp.Flushed, ShouldBeTrue)
+	})
+}
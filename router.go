@@ -3,6 +3,7 @@ package water
 import (
 	"fmt"
 	"net/http"
+	"reflect"
 	"strings"
 	"sync"
 
@@ -58,6 +59,19 @@ type route struct {
 	uri        string // raw uri
 	variantUri string // variant uri, httprouter route compatible
 	handlers   []Handler
+
+	reqType  reflect.Type // only for route ends with Typed()
+	respType reflect.Type
+}
+
+// RouteInfo represents a route for introspection.
+type RouteInfo struct {
+	Method   string
+	Path     string
+	Handlers int // include middleware
+	// Request and Response are nil unless the route ends with a Typed handler
+	Request  reflect.Type
+	Response reflect.Type
 }
 
 // routeStore represents a thread-safe store for route uri.
@@ -111,6 +125,8 @@ type Router struct {
 
 	parent *Router
 	sub    []*Router
+
+	services map[reflect.Type]*service // see Provide()
 }
 
 func NewRouter() *Router {
@@ -214,18 +230,18 @@ func (r *Router) HEAD(pattern string, handlers ...interface{}) {
 }
 
 // add all route to routeStore
-func dumpRoute(r *Router, rs *routeStore) {
+func dumpRoute(r *Router, rs *routeStore, o *options) {
 	if r.sub == nil { // end route
-		rs.add(getRoute(r))
+		rs.add(getRoute(r, o))
 		return
 	}
 
 	for _, v := range r.sub {
-		dumpRoute(v, rs)
+		dumpRoute(v, rs, o)
 	}
 }
 
-func getRoute(r *Router) *route {
+func getRoute(r *Router, o *options) *route {
 	ps := []string{}
 	hs := []interface{}{}
 
@@ -262,13 +278,20 @@ func getRoute(r *Router) *route {
 	re := &route{
 		method:   r.method,
 		uri:      strings.Join(reverseStrings(ps), ""),
-		handlers: newHandlers(hs),
+		handlers: make([]Handler, len(hs)),
+	}
+	for i, h := range hs {
+		re.handlers[i] = newInjectHandler(h, r, o)
 	}
 
 	if len(re.handlers) == 0 {
 		panic(fmt.Sprintf("handler err : empty handlers in route(%s,%s)", re.method, re.uri))
 	}
 
+	if ts, ok := re.handlers[len(re.handlers)-1].(typedSignature); ok {
+		re.reqType, re.respType = ts.signature()
+	}
+
 	return re
 }
 
@@ -283,10 +306,14 @@ func (r *Router) Handler(opts ...Option) *Engine {
 		panic("sub router not allowed: Handler()")
 	}
 
-	o := &options{}
+	o := new(options)
+	*o = defaultOptions
 	for _, f := range opts {
 		f(o)
 	}
+	if o.CookiePolicy.Path == "" {
+		o.CookiePolicy.Path = "/"
+	}
 
 	// for global middleware
 	if len(o.NoFoundHandlers) > 0 && len(r.gbefores) > 0 {
@@ -299,7 +326,7 @@ func (r *Router) Handler(opts ...Option) *Engine {
 
 	rs := newRouteStore()
 
-	dumpRoute(r, rs)
+	dumpRoute(r, rs, o)
 
 	// if len(rs.routeSlice) == 0 {
 	// 	panic("no route: Handler()")
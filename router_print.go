@@ -89,7 +89,8 @@ func (e *Engine) PrintRawRoutes(method string) {
 }
 
 // order by add router order
-// output: [method : count(handler)] uri
+// output: [method : count(handler)] uri [req -> resp]
+// "req -> resp" only for the route ends with Typed()
 func (e *Engine) PrintRawAllRoutes() {
 	if len(e.routeStore.routeSlice) == 0 {
 		fmt.Printf("%s\n", "no route")
@@ -98,10 +99,30 @@ func (e *Engine) PrintRawAllRoutes() {
 
 	for _, v := range e.routeStore.routeSlice {
 		// count(router.handlers) + uri
-		fmt.Printf("[%-7s : %d] %s\n", v.method, len(v.handlers), v.uri)
+		if v.reqType != nil {
+			fmt.Printf("[%-7s : %d] %s [%s -> %s]\n", v.method, len(v.handlers), v.uri, v.reqType, v.respType)
+		} else {
+			fmt.Printf("[%-7s : %d] %s\n", v.method, len(v.handlers), v.uri)
+		}
 	}
 }
 
+// Routes returns all routes, order by add router order
+func (e *Engine) Routes() []RouteInfo {
+	ls := make([]RouteInfo, len(e.routeStore.routeSlice))
+	for i, v := range e.routeStore.routeSlice {
+		ls[i] = RouteInfo{
+			Method:   v.method,
+			Path:     v.uri,
+			Handlers: len(v.handlers),
+			Request:  v.reqType,
+			Response: v.respType,
+		}
+	}
+
+	return ls
+}
+
 // print release router tree by method
 // len(tree.handlers) includes middleware
 func (e *Engine) PrintRouterTree(method string) {
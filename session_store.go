@@ -0,0 +1,216 @@
+package water
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/gob"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrSessionCookieInvalid  = errors.New("water: invalid session cookie")
+	ErrSessionCookieTooLarge = errors.New("water: session cookie is too large")
+)
+
+// maxCookieSize is the max size of a cookie value which browsers keep.
+const maxCookieSize = 4096
+
+// CookieStore keeps the values in the signed cookie, the values are encoded by
+// encoding/gob, so custom types need gob.Register().
+// The first key signs the cookie and all keys verify it, so keys can be rotated.
+type CookieStore struct {
+	keys CookieKeys
+}
+
+// NewCookieStore returns a CookieStore, panic if no key.
+func NewCookieStore(keys ...[]byte) *CookieStore {
+	if len(keys) == 0 {
+		panic("no key for CookieStore")
+	}
+
+	return &CookieStore{keys: CookieKeys(keys)}
+}
+
+type cookieSession struct {
+	Expires int64
+	Values  map[string]interface{}
+}
+
+func (s *CookieStore) Load(id string) (map[string]interface{}, error) {
+	i := strings.IndexByte(id, '.')
+	if i < 0 {
+		return nil, ErrSessionCookieInvalid
+	}
+	payload, sig := id[:i], id[i+1:]
+
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil {
+		return nil, ErrSessionCookieInvalid
+	}
+	if !s.keys.verify(payload, mac) {
+		return nil, ErrSessionCookieInvalid
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrSessionCookieInvalid
+	}
+
+	var cs cookieSession
+	if err = gob.NewDecoder(bytes.NewReader(data)).Decode(&cs); err != nil {
+		return nil, err
+	}
+	if time.Now().Unix() > cs.Expires {
+		return nil, nil
+	}
+	if cs.Values == nil {
+		cs.Values = make(map[string]interface{})
+	}
+
+	return cs.Values, nil
+}
+
+func (s *CookieStore) Save(id string, values map[string]interface{}, maxAge time.Duration) (string, error) {
+	var buf bytes.Buffer
+	cs := cookieSession{
+		Expires: time.Now().Add(maxAge).Unix(),
+		Values:  values,
+	}
+	if err := gob.NewEncoder(&buf).Encode(&cs); err != nil {
+		return "", err
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(buf.Bytes())
+	v := payload + "." + base64.RawURLEncoding.EncodeToString(s.keys.sign(payload))
+	if len(v) > maxCookieSize {
+		return "", ErrSessionCookieTooLarge
+	}
+
+	return v, nil
+}
+
+// Delete does nothing, the cookie is expired by Session.
+func (s *CookieStore) Delete(id string) error {
+	return nil
+}
+
+// MemoryStore keeps the sessions in memory, for single instance or testing.
+type MemoryStore struct {
+	mu       sync.Mutex
+	sessions map[string]memorySession
+	stop     chan struct{}
+	once     sync.Once
+}
+
+type memorySession struct {
+	values  map[string]interface{}
+	expires time.Time
+}
+
+// NewMemoryStore returns a MemoryStore which sweeps the expired sessions
+// every sweep, no sweeping if sweep <= 0. Close() stops the sweeping.
+func NewMemoryStore(sweep time.Duration) *MemoryStore {
+	s := &MemoryStore{
+		sessions: make(map[string]memorySession),
+		stop:     make(chan struct{}),
+	}
+
+	if sweep > 0 {
+		go func() {
+			t := time.NewTicker(sweep)
+			defer t.Stop()
+
+			for {
+				select {
+				case <-t.C:
+					s.Sweep()
+				case <-s.stop:
+					return
+				}
+			}
+		}()
+	}
+
+	return s
+}
+
+func (s *MemoryStore) Load(id string) (map[string]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ms, ok := s.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+	if time.Now().After(ms.expires) {
+		delete(s.sessions, id)
+		return nil, nil
+	}
+
+	return copyValues(ms.values), nil
+}
+
+func (s *MemoryStore) Save(id string, values map[string]interface{}, maxAge time.Duration) (string, error) {
+	if id == "" {
+		var err error
+		if id, err = newSessionID(); err != nil {
+			return "", err
+		}
+	}
+
+	s.mu.Lock()
+	s.sessions[id] = memorySession{
+		values:  copyValues(values),
+		expires: time.Now().Add(maxAge),
+	}
+	s.mu.Unlock()
+
+	return id, nil
+}
+
+func (s *MemoryStore) Delete(id string) error {
+	s.mu.Lock()
+	delete(s.sessions, id)
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Sweep deletes the expired sessions.
+func (s *MemoryStore) Sweep() {
+	now := time.Now()
+
+	s.mu.Lock()
+	for id, ms := range s.sessions {
+		if now.After(ms.expires) {
+			delete(s.sessions, id)
+		}
+	}
+	s.mu.Unlock()
+}
+
+// Len returns the number of sessions, including the expired but not swept.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.sessions)
+}
+
+// Close stops the sweeping.
+func (s *MemoryStore) Close() error {
+	s.once.Do(func() { close(s.stop) })
+	return nil
+}
+
+func copyValues(m map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+
+	return c
+}
@@ -0,0 +1,228 @@
+package water
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// SSEHeartbeat is the interval of the heartbeat comment sent by ctx.StreamSSE
+	// to keep the connection alive through proxies, 0 means no heartbeat.
+	SSEHeartbeat = 15 * time.Second
+)
+
+// ServerSentEvent is a Server-Sent Event, see https://html.spec.whatwg.org/multipage/server-sent-events.html
+type ServerSentEvent struct {
+	ID    string
+	Event string
+	// Retry tells client the reconnection time, 0 means not send
+	Retry time.Duration
+	// Data is written as is if it is string or []byte, otherwise as JSON
+	Data interface{}
+}
+
+// SSEWriter writes Server-Sent Events to client, it is safe for concurrent use.
+// Every write is flushed if the underlying http.ResponseWriter supports,
+// otherwise client receives events after the handler returns.
+type SSEWriter struct {
+	ctx *Context
+
+	lock sync.Mutex
+	err  error
+}
+
+func (ctx *Context) sseWriter() *SSEWriter {
+	if !ctx.Written() {
+		h := ctx.Header()
+		h.Set(HeaderContentType, MIMETextEventStream)
+		h.Set(HeaderCacheControl, "no-cache")
+		h.Set("Connection", "keep-alive")
+		h.Set("X-Accel-Buffering", "no") // for nginx
+		ctx.WriteHeader(http.StatusOK)
+	}
+
+	return &SSEWriter{ctx: ctx}
+}
+
+// SSEvent sends an event with name and data to client.
+func (ctx *Context) SSEvent(event string, data interface{}) error {
+	return ctx.sseWriter().Send(ServerSentEvent{Event: event, Data: data})
+}
+
+// StreamSSE calls step until it returns false, the client disconnects or
+// a write fails. A heartbeat comment is sent every SSEHeartbeat meanwhile.
+// step should select on w.Done() when it blocks.
+// use:
+//
+//	ctx.StreamSSE(func(w *water.SSEWriter) bool {
+//	    select {
+//	    case msg := <-messages:
+//	        w.Send(water.ServerSentEvent{ID: msg.ID, Event: "message", Data: msg})
+//	        return true
+//	    case <-w.Done():
+//	        return false
+//	    }
+//	})
+func (ctx *Context) StreamSSE(step func(w *SSEWriter) bool) error {
+	w := ctx.sseWriter()
+	w.flush()
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	defer func() {
+		close(done)
+		wg.Wait()
+	}()
+
+	if SSEHeartbeat > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			t := time.NewTicker(SSEHeartbeat)
+			defer t.Stop()
+
+			for {
+				select {
+				case <-done:
+					return
+				case <-w.Done():
+					return
+				case <-t.C:
+					if w.Comment("heartbeat") != nil {
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	for {
+		select {
+		case <-w.Done():
+			return ctx.Request.Context().Err()
+		default:
+		}
+
+		if !step(w) {
+			return w.Err()
+		}
+		if err := w.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// Done is closed when the client disconnects.
+func (w *SSEWriter) Done() <-chan struct{} {
+	return w.ctx.Request.Context().Done()
+}
+
+// Err returns the first write error.
+func (w *SSEWriter) Err() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	return w.err
+}
+
+// Send sends e to client.
+func (w *SSEWriter) Send(e ServerSentEvent) error {
+	buf := new(bytes.Buffer)
+
+	if e.ID != "" {
+		writeSSEField(buf, "id", e.ID)
+	}
+	if e.Event != "" {
+		writeSSEField(buf, "event", e.Event)
+	}
+	if e.Retry > 0 {
+		writeSSEField(buf, "retry", strconv.FormatInt(int64(e.Retry/time.Millisecond), 10))
+	}
+
+	switch v := e.Data.(type) {
+	case nil:
+	case string:
+		writeSSEData(buf, v)
+	case []byte:
+		writeSSEData(buf, string(v))
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		writeSSEData(buf, string(data))
+	}
+	buf.WriteByte('\n')
+
+	return w.write(buf.Bytes())
+}
+
+// Event sends an event with name and data to client.
+func (w *SSEWriter) Event(event string, data interface{}) error {
+	return w.Send(ServerSentEvent{Event: event, Data: data})
+}
+
+// Comment sends a comment which is ignored by client.
+func (w *SSEWriter) Comment(s string) error {
+	buf := new(bytes.Buffer)
+	for _, line := range splitSSELines(s) {
+		buf.WriteString(": ")
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+
+	return w.write(buf.Bytes())
+}
+
+func (w *SSEWriter) write(data []byte) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.err != nil {
+		return w.err
+	}
+	if err := w.ctx.Request.Context().Err(); err != nil {
+		w.err = err
+		return err
+	}
+
+	if _, err := w.ctx.Write(data); err != nil {
+		w.err = err
+		return err
+	}
+	w.ctx.rw.FlushError()
+
+	return nil
+}
+
+func (w *SSEWriter) flush() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.ctx.rw.FlushError()
+}
+
+// writeSSEField writes "name: value\n", the newlines in value are removed.
+func writeSSEField(w io.Writer, name, value string) {
+	value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+	io.WriteString(w, name+": "+value+"\n")
+}
+
+// writeSSEData writes each line of data as a "data:" field.
+func writeSSEData(w io.Writer, data string) {
+	for _, line := range splitSSELines(data) {
+		io.WriteString(w, "data: "+line+"\n")
+	}
+}
+
+func splitSSELines(s string) []string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.Split(strings.ReplaceAll(s, "\r", "\n"), "\n")
+}
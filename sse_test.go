@@ -0,0 +1,66 @@
+package water
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSSE(t *testing.T) {
+	Convey("ctx.SSEvent", t, func() {
+		resp := serveRender(func(ctx *Context) {
+			ctx.SSEvent("ping", "a\nb")
+			ctx.SSEvent("user", H{"id": 1})
+		}, "/")
+
+		So(resp.Header().Get(HeaderContentType), ShouldEqual, MIMETextEventStream)
+		So(resp.Flushed, ShouldBeTrue)
+		So(resp.Body.String(), ShouldEqual, "event: ping\ndata: a\ndata: b\n\nevent: user\ndata: {\"id\":1}\n\n")
+	})
+
+	Convey("ctx.StreamSSE", t, func() {
+		old := SSEHeartbeat
+		SSEHeartbeat = 10 * time.Millisecond
+		defer func() { SSEHeartbeat = old }()
+
+		n := 0
+		resp := serveRender(func(ctx *Context) {
+			err := ctx.StreamSSE(func(w *SSEWriter) bool {
+				n++
+				if n == 2 {
+					time.Sleep(50 * time.Millisecond)
+				}
+				w.Send(ServerSentEvent{ID: "1\n2", Event: "n", Retry: time.Second, Data: n})
+				return n < 3
+			})
+			So(err, ShouldBeNil)
+		}, "/")
+
+		body := resp.Body.String()
+		So(strings.Count(body, "id: 12\nevent: n\nretry: 1000\ndata: "), ShouldEqual, 3)
+		So(body, ShouldContainSubstring, ": heartbeat\n\n")
+	})
+
+	Convey("ctx.StreamSSE stops when client disconnects", t, func() {
+		r := NewRouter()
+		r.GET("/", func(ctx *Context) {
+			err := ctx.StreamSSE(func(w *SSEWriter) bool {
+				<-w.Done()
+				return true
+			})
+			So(err, ShouldEqual, context.Canceled)
+		})
+		e := r.Handler()
+
+		c, cancel := context.WithCancel(context.Background())
+		req, _ := http.NewRequestWithContext(c, "GET", "/", nil)
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		e.ServeHTTP(httptest.NewRecorder(), req)
+	})
+}
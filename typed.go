@@ -0,0 +1,130 @@
+package water
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+
+	"github.com/meilihao/water/binding"
+)
+
+// typedSignature is implemented by the handlers built by Typed,
+// route introspection uses it to list the request and response types.
+type typedSignature interface {
+	signature() (req, resp reflect.Type)
+}
+
+type typedHandler[Req, Resp any] struct {
+	fn func(*Context, Req) (Resp, error)
+
+	reqType  reflect.Type
+	respType reflect.Type
+
+	reqIsPtr    bool // Req is *T, need to allocate T before binding
+	respEmpty   bool // Resp is struct{}, always 204
+	respNilAble bool
+}
+
+// Typed adapts fn to a Handler:
+//   - Req is bound from uri params, query, headers and body, reply 400 if
+//     failed. Then it is validated, reply 422 with binding.FieldErrors if failed.
+//   - Resp is rendered as JSON or XML by ctx.Negotiate, 201 for POST,
+//     204 for an empty Resp and 200 for others.
+//   - err is replied with its StatusCode() if it has one, otherwise 500.
+//
+// use:
+//
+//	router.POST("/user/<id:int>", water.Typed(func(ctx *water.Context, req CreateUserReq) (*User, error) {
+//	    ...
+//	}))
+func Typed[Req, Resp any](fn func(*Context, Req) (Resp, error)) Handler {
+	if fn == nil {
+		panic("handler err : nil typed handler")
+	}
+
+	h := &typedHandler[Req, Resp]{
+		fn:       fn,
+		reqType:  reflect.TypeOf((*Req)(nil)).Elem(),
+		respType: reflect.TypeOf((*Resp)(nil)).Elem(),
+	}
+
+	h.reqIsPtr = h.reqType.Kind() == reflect.Ptr
+	switch h.respType.Kind() {
+	case reflect.Struct:
+		h.respEmpty = h.respType.NumField() == 0
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		h.respNilAble = true
+	}
+
+	return h
+}
+
+func (h *typedHandler[Req, Resp]) signature() (reflect.Type, reflect.Type) {
+	return h.reqType, h.respType
+}
+
+func (h *typedHandler[Req, Resp]) ServeHTTP(ctx *Context) {
+	var req Req
+
+	var target interface{} = &req
+	if h.reqIsPtr {
+		v := reflect.New(h.reqType.Elem())
+		reflect.ValueOf(&req).Elem().Set(v)
+		target = v.Interface()
+	}
+
+	if err := ctx.bindAll(target); err != nil {
+		if es := binding.NewFieldErrors(target, err, nil); len(es) > 0 {
+			ctx.typedRender(es.StatusCode(), errorBody{Error: "validation failed", Fields: es})
+			return
+		}
+
+		code := http.StatusBadRequest
+		if errors.As(err, new(*binding.BodyTooLargeError)) {
+			code = http.StatusRequestEntityTooLarge
+		} else if errors.As(err, new(*binding.UnsupportedMediaTypeError)) {
+			code = http.StatusUnsupportedMediaType
+		}
+		ctx.typedError(code, err)
+		return
+	}
+
+	resp, err := h.fn(ctx, req)
+	if ctx.Written() {
+		return
+	}
+	if err != nil {
+		ctx.typedError(statusOfError(err), err)
+		return
+	}
+
+	if h.respEmpty || (h.respNilAble && reflect.ValueOf(&resp).Elem().IsNil()) {
+		ctx.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	code := http.StatusOK
+	if ctx.Request.Method == http.MethodPost {
+		code = http.StatusCreated
+	}
+	ctx.typedRender(code, resp)
+}
+
+// typedError hides the message of unknown 5xx errors.
+func (ctx *Context) typedError(code int, err error) {
+	msg := err.Error()
+	if code >= http.StatusInternalServerError {
+		var he *HTTPError
+		if errors.As(err, &he) {
+			msg = he.Message
+		} else {
+			msg = http.StatusText(code)
+		}
+	}
+
+	ctx.typedRender(code, errorBody{Error: msg})
+}
+
+func (ctx *Context) typedRender(code int, v interface{}) {
+	ctx.Negotiate(code, Offers{JSON: v, XML: v})
+}
@@ -0,0 +1,96 @@
+package water
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type typedUserReq struct {
+	ID    int    `uri:"id"`
+	Token string `header:"X-Token"`
+	Lang  string `form:"lang"`
+	Name  string `json:"name" binding:"required"`
+}
+
+type typedUserResp struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Lang  string `json:"lang"`
+	Token string `json:"token"`
+}
+
+func TestTyped(t *testing.T) {
+	Convey("Typed handler", t, func() {
+		r := NewRouter()
+		r.POST("/user/<id:int>", Typed(func(ctx *Context, req typedUserReq) (*typedUserResp, error) {
+			if req.Name == "nobody" {
+				return nil, NewHTTPError(http.StatusConflict, "conflict")
+			}
+			if req.Name == "empty" {
+				return nil, nil
+			}
+			if req.Name == "busy" {
+				return nil, fmt.Errorf("query db: %w", NewHTTPError(http.StatusServiceUnavailable, "busy"))
+			}
+			if req.Name == "broken" {
+				return nil, errors.New("dial tcp 10.0.0.1:5432: refused")
+			}
+
+			return &typedUserResp{ID: req.ID, Name: req.Name, Lang: req.Lang, Token: req.Token}, nil
+		}))
+		e := r.Handler()
+
+		serveUser := func(body, accept string) *httptest.ResponseRecorder {
+			return serve(e, "POST", "http://localhost:8080/user/7?lang=zh", body,
+				HeaderContentType, MIMEApplicationJSON, "X-Token", "abc", HeaderAccept, accept)
+		}
+
+		resp := serveUser(`{"name":"water"}`, "")
+		So(resp.Code, ShouldEqual, http.StatusCreated)
+		So(resp.Body.String(), ShouldEqual, `{"id":7,"name":"water","lang":"zh","token":"abc"}`+"\n")
+
+		resp = serveUser(`{}`, "")
+		So(resp.Code, ShouldEqual, http.StatusUnprocessableEntity)
+		So(resp.Body.String(), ShouldEqual, `{"error":"validation failed","fields":[{"field":"name","rule":"required","value":"","message":"name is required"}]}`+"\n")
+
+		resp = serveUser(`{"name":`, "")
+		So(resp.Code, ShouldEqual, http.StatusBadRequest)
+		So(resp.Body.String(), ShouldStartWith, `{"error":"`)
+		So(resp.Body.String(), ShouldNotContainSubstring, `"fields"`)
+
+		resp = serveUser(`{"name":"nobody"}`, "application/xml")
+		So(resp.Code, ShouldEqual, http.StatusConflict)
+		So(resp.Body.String(), ShouldEqual, `<error><message>conflict</message></error>`)
+
+		resp = serveUser(`{"name":"empty"}`, "")
+		So(resp.Code, ShouldEqual, http.StatusNoContent)
+
+		resp = serveUser(`{"name":"busy"}`, "")
+		So(resp.Code, ShouldEqual, http.StatusServiceUnavailable)
+		So(resp.Body.String(), ShouldEqual, `{"error":"busy"}`+"\n")
+
+		resp = serveUser(`{"name":"broken"}`, "")
+		So(resp.Code, ShouldEqual, http.StatusInternalServerError)
+		So(resp.Body.String(), ShouldEqual, `{"error":"Internal Server Error"}`+"\n")
+	})
+
+	Convey("Typed route introspection", t, func() {
+		r := NewRouter()
+		r.GET("/a", Typed(func(ctx *Context, req typedUserReq) (typedUserResp, error) {
+			return typedUserResp{}, nil
+		}))
+		r.GET("/b", test)
+		e := r.Handler()
+
+		routes := e.Routes()
+		So(len(routes), ShouldEqual, 2)
+		So(routes[0].Request.String(), ShouldEqual, "water.typedUserReq")
+		So(routes[0].Response.String(), ShouldEqual, "water.typedUserResp")
+		So(routes[1].Request, ShouldBeNil)
+	})
+}
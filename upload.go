@@ -0,0 +1,432 @@
+package water
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/meilihao/water/binding"
+)
+
+// UploadPart is a file part of the multipart body streamed by ctx.StreamUploads.
+type UploadPart struct {
+	*multipart.Part
+	// ContentType is sniffed from the content by http.DetectContentType,
+	// the Content-Type header of part is not trusted.
+	ContentType string
+}
+
+// Sink receives the content of an UploadPart.
+type Sink interface {
+	io.Writer
+	// Commit is called after the whole part is written.
+	Commit() error
+	// Abort discards the written content. It is called instead of Commit on
+	// error, or after Commit if a later part fails.
+	Abort() error
+}
+
+// UploadedFile is a file part committed to its Sink.
+type UploadedFile struct {
+	Field       string
+	Filename    string
+	ContentType string // sniffed
+	Size        int64
+	Hash        []byte // sum of UploadHash, nil if not set
+	Sink        Sink
+}
+
+type uploadOptions struct {
+	maxFileSize  int64
+	maxTotalSize int64
+	allowTypes   []string
+	hash         func() hash.Hash
+}
+
+// UploadOption configures ctx.StreamUploads.
+type UploadOption func(*uploadOptions)
+
+// UploadMaxFileSize limits every file to n bytes, 0 means no limit.
+func UploadMaxFileSize(n int64) UploadOption {
+	return func(o *uploadOptions) {
+		o.maxFileSize = n
+	}
+}
+
+// UploadMaxTotalSize limits the content of all parts to n bytes, 0 means no limit.
+func UploadMaxTotalSize(n int64) UploadOption {
+	return func(o *uploadOptions) {
+		o.maxTotalSize = n
+	}
+}
+
+// UploadAllowTypes allows only the files whose sniffed type matches one of
+// types, e.g. "image/png" or "image/*". All types are allowed by default.
+func UploadAllowTypes(types ...string) UploadOption {
+	return func(o *uploadOptions) {
+		o.allowTypes = append(o.allowTypes, types...)
+	}
+}
+
+// UploadHash hashes every file by h while streaming, e.g. sha256.New.
+func UploadHash(h func() hash.Hash) UploadOption {
+	return func(o *uploadOptions) {
+		o.hash = h
+	}
+}
+
+// FileTooLargeError is returned when a file exceeds UploadMaxFileSize.
+type FileTooLargeError struct {
+	Field    string
+	Filename string
+	Limit    int64
+}
+
+func (e *FileTooLargeError) Error() string {
+	return fmt.Sprintf("file %q of %s too large, limit is %d bytes", e.Filename, e.Field, e.Limit)
+}
+
+// StatusCode returns 413.
+func (e *FileTooLargeError) StatusCode() int {
+	return http.StatusRequestEntityTooLarge
+}
+
+// FileTypeError is returned when the sniffed type of a file is not allowed
+// by UploadAllowTypes.
+type FileTypeError struct {
+	Field       string
+	Filename    string
+	ContentType string
+}
+
+func (e *FileTypeError) Error() string {
+	return fmt.Sprintf("file %q of %s has disallowed type %q", e.Filename, e.Field, e.ContentType)
+}
+
+// StatusCode returns 415.
+func (e *FileTypeError) StatusCode() int {
+	return http.StatusUnsupportedMediaType
+}
+
+// StreamUploads reads the multipart body part by part, unlike ParseFormOrMultipartForm
+// which buffers or spills the whole body before the handler runs.
+// fn returns the Sink to write a file part to, or nil to skip it. The other
+// parts are kept in ctx.Request.Form, so ctx.Query and the bindings still work.
+// On error all Sinks are aborted, and it replies the status carried by the
+// error: 413 if a file or the total exceeds the limit, 415 if the body is not
+// multipart or the type of a file is not allowed.
+// Like ParseMultipartForm, at most 1000 parts are read, and the name and
+// header of every part are charged to the limits besides the content.
+//
+//	files, err := ctx.StreamUploads(func(p *water.UploadPart) (water.Sink, error) {
+//		return water.NewDiskSink(filepath.Join(dir, filepath.Base(p.FileName())))
+//	}, water.UploadMaxFileSize(10<<20), water.UploadAllowTypes("image/*"), water.UploadHash(sha256.New))
+func (ctx *Context) StreamUploads(fn func(*UploadPart) (Sink, error), opts ...UploadOption) ([]*UploadedFile, error) {
+	u := &uploader{}
+	for _, opt := range opts {
+		opt(&u.opts)
+	}
+
+	mr, err := ctx.Request.MultipartReader()
+	if err != nil {
+		if err == http.ErrNotMultipart {
+			err = &binding.UnsupportedMediaTypeError{MediaType: ctx.ContentType()}
+		}
+		return nil, ctx.abortUpload(err)
+	}
+	ctx.parsedParams = true // the body is consumed by the parts
+
+	u.total = limitReader{n: -1}
+	if u.opts.maxTotalSize > 0 {
+		u.total = limitReader{n: u.opts.maxTotalSize, err: &binding.BodyTooLargeError{Limit: u.opts.maxTotalSize}}
+	}
+	u.valueLeft = defaultMultipartMemory + maxUploadValueBytes
+
+	values := make(url.Values)
+	defer ctx.setUploadForm(values)
+
+	var files []*UploadedFile
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			return files, nil
+		}
+
+		if err == nil {
+			err = u.nextPart(p)
+		}
+		if err == nil {
+			u.total.r = p
+			if p.FileName() == "" {
+				err = u.readValue(p, values)
+			} else {
+				var f *UploadedFile
+				if f, err = u.readFile(p, fn); f != nil {
+					files = append(files, f)
+				}
+			}
+			p.Close()
+		}
+
+		if err != nil {
+			for _, f := range files {
+				f.Sink.Abort()
+			}
+			return nil, ctx.abortUpload(binding.BodyError(err))
+		}
+	}
+}
+
+// abortUpload replies the status carried by err, and returns err.
+func (ctx *Context) abortUpload(err error) error {
+	var sc interface{ StatusCode() int }
+	if !ctx.Written() && errors.As(err, &sc) {
+		ctx.Abort(sc.StatusCode())
+	}
+
+	return err
+}
+
+// setUploadForm sets the values of the parts and the query like ParseMultipartForm.
+func (ctx *Context) setUploadForm(values url.Values) {
+	form := make(url.Values, len(values))
+	for k, vs := range values {
+		form[k] = append(form[k], vs...)
+	}
+	for k, vs := range ctx.Request.URL.Query() {
+		form[k] = append(form[k], vs...)
+	}
+
+	ctx.Request.Form = form
+	ctx.Request.PostForm = values
+	ctx.Request.MultipartForm = &multipart.Form{Value: values, File: map[string][]*multipart.FileHeader{}}
+}
+
+// The limits of the parts, the same as ParseMultipartForm.
+const (
+	// maxUploadValueBytes is the bytes allowed for the values of parts
+	// besides MaxMultipartMemory.
+	maxUploadValueBytes = 10 << 20
+	maxUploadParts      = 1000
+	// uploadPartOverhead is charged for the header of every part.
+	uploadPartOverhead = 200
+)
+
+type uploader struct {
+	opts      uploadOptions
+	total     limitReader // shared by all parts
+	valueLeft int64       // bytes left for the values of parts
+	parts     int
+}
+
+// nextPart counts p and charges its names and header to the limits.
+func (u *uploader) nextPart(p *multipart.Part) error {
+	if u.parts++; u.parts > maxUploadParts {
+		return NewHTTPError(http.StatusRequestEntityTooLarge, fmt.Sprintf("too many parts, limit is %d", maxUploadParts))
+	}
+
+	n := int64(len(p.FormName())+len(p.FileName())) + uploadPartOverhead
+	if u.valueLeft -= n; u.valueLeft < 0 {
+		return &binding.BodyTooLargeError{Limit: defaultMultipartMemory + maxUploadValueBytes}
+	}
+	if u.total.n >= 0 {
+		if u.total.n < n {
+			return u.total.err
+		}
+		u.total.n -= n
+	}
+
+	return nil
+}
+
+func (u *uploader) readValue(p *multipart.Part, values url.Values) error {
+	r := &limitReader{r: &u.total, n: u.valueLeft, err: &binding.BodyTooLargeError{Limit: defaultMultipartMemory + maxUploadValueBytes}}
+
+	var b strings.Builder
+	if _, err := io.Copy(&b, r); err != nil {
+		return err
+	}
+	u.valueLeft = r.n
+
+	name := p.FormName()
+	values[name] = append(values[name], b.String())
+
+	return nil
+}
+
+func (u *uploader) readFile(p *multipart.Part, fn func(*UploadPart) (Sink, error)) (*UploadedFile, error) {
+	var r io.Reader = &u.total
+	if u.opts.maxFileSize > 0 {
+		r = &limitReader{r: r, n: u.opts.maxFileSize, err: &FileTooLargeError{
+			Field:    p.FormName(),
+			Filename: p.FileName(),
+			Limit:    u.opts.maxFileSize,
+		}}
+	}
+
+	sniff := make([]byte, 512)
+	n, err := io.ReadFull(r, sniff)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	sniff = sniff[:n]
+
+	contentType := http.DetectContentType(sniff)
+	if !u.opts.allowed(contentType) {
+		return nil, &FileTypeError{Field: p.FormName(), Filename: p.FileName(), ContentType: contentType}
+	}
+
+	sink, err := fn(&UploadPart{Part: p, ContentType: contentType})
+	if err != nil {
+		return nil, err
+	}
+	if sink == nil {
+		_, err = io.Copy(io.Discard, r)
+		return nil, err
+	}
+
+	var h hash.Hash
+	w := io.Writer(sink)
+	if u.opts.hash != nil {
+		h = u.opts.hash()
+		w = io.MultiWriter(sink, h)
+	}
+
+	size, err := io.Copy(w, io.MultiReader(bytes.NewReader(sniff), r))
+	if err == nil {
+		err = sink.Commit()
+	}
+	if err != nil {
+		sink.Abort()
+		return nil, err
+	}
+
+	f := &UploadedFile{
+		Field:       p.FormName(),
+		Filename:    p.FileName(),
+		ContentType: contentType,
+		Size:        size,
+		Sink:        sink,
+	}
+	if h != nil {
+		f.Hash = h.Sum(nil)
+	}
+
+	return f, nil
+}
+
+func (o *uploadOptions) allowed(contentType string) bool {
+	if len(o.allowTypes) == 0 {
+		return true
+	}
+
+	mediaType, _ := head(contentType, ";")
+	for _, t := range o.allowTypes {
+		if t == "*/*" || t == mediaType ||
+			(strings.HasSuffix(t, "/*") && strings.HasPrefix(mediaType, t[:len(t)-1])) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// limitReader reads at most n bytes from r, reading more gets err.
+// n < 0 means no limit.
+type limitReader struct {
+	r   io.Reader
+	n   int64
+	err error
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	if l.n < 0 {
+		return l.r.Read(p)
+	}
+
+	if int64(len(p)) > l.n {
+		p = p[:l.n+1]
+	}
+	n, err := l.r.Read(p)
+	if int64(n) > l.n {
+		n, l.n = int(l.n), 0
+		return n, l.err
+	}
+	l.n -= int64(n)
+
+	return n, err
+}
+
+// DiskSink writes an upload to a temporary file beside Path, which is linked
+// to Path on Commit. An existing Path is never overwritten, Commit fails with
+// fs.ErrExist instead. Abort removes the temporary file, or Path if committed,
+// so only the files created by this upload are removed.
+type DiskSink struct {
+	Path string
+
+	f         *os.File
+	committed bool
+}
+
+// NewDiskSink creates the temporary file of a DiskSink for path.
+func NewDiskSink(path string) (*DiskSink, error) {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.part")
+	if err != nil {
+		return nil, err
+	}
+
+	return &DiskSink{Path: path, f: f}, nil
+}
+
+func (s *DiskSink) Write(p []byte) (int, error) {
+	return s.f.Write(p)
+}
+
+func (s *DiskSink) Commit() error {
+	if err := s.f.Close(); err != nil {
+		return err
+	}
+	// unlike rename, link fails if Path exists
+	if err := os.Link(s.f.Name(), s.Path); err != nil {
+		return err
+	}
+	s.committed = true
+
+	return os.Remove(s.f.Name())
+}
+
+func (s *DiskSink) Abort() error {
+	s.f.Close()
+
+	err := os.Remove(s.f.Name())
+	if s.committed {
+		err = os.Remove(s.Path)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// MemorySink keeps an upload in memory, Abort discards it.
+type MemorySink struct {
+	bytes.Buffer
+}
+
+func (s *MemorySink) Commit() error {
+	return nil
+}
+
+func (s *MemorySink) Abort() error {
+	s.Reset()
+	return nil
+}
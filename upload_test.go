@@ -0,0 +1,236 @@
+package water
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"io/fs"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/meilihao/water/binding"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n")
+
+func TestStreamUploads(t *testing.T) {
+	type file struct {
+		field, name string
+		data        []byte
+	}
+
+	serveUpload := func(h func(ctx *Context), files []file, fields map[string]string) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		for k, v := range fields {
+			mw.WriteField(k, v)
+		}
+		for _, f := range files {
+			w, _ := mw.CreateFormFile(f.field, f.name)
+			w.Write(f.data)
+		}
+		mw.Close()
+
+		return serve(newTestEngine("POST", "/upload", h), "POST", "/upload?id=1", body.String(),
+			HeaderContentType, mw.FormDataContentType())
+	}
+
+	png := append(append([]byte{}, pngHeader...), bytes.Repeat([]byte("p"), 1000)...)
+	text := []byte("hello")
+
+	Convey("stream to memory with hash", t, func() {
+		var sinks []*MemorySink
+		resp := serveUpload(func(ctx *Context) {
+			files, err := ctx.StreamUploads(func(p *UploadPart) (Sink, error) {
+				s := &MemorySink{}
+				sinks = append(sinks, s)
+				return s, nil
+			}, UploadHash(sha256.New))
+			So(err, ShouldBeNil)
+			So(files, ShouldHaveLength, 2)
+
+			sum := sha256.Sum256(png)
+			So(files[0].Field, ShouldEqual, "img")
+			So(files[0].Filename, ShouldEqual, "a.png")
+			So(files[0].ContentType, ShouldEqual, "image/png")
+			So(files[0].Size, ShouldEqual, len(png))
+			So(files[0].Hash, ShouldResemble, sum[:])
+			So(files[1].ContentType, ShouldEqual, "text/plain; charset=utf-8")
+
+			So(ctx.Query("name"), ShouldEqual, "water")
+			So(ctx.Query("id"), ShouldEqual, "1")
+
+			var obj struct {
+				Name string `form:"name"`
+			}
+			So(ctx.BindWith(&obj, binding.Form), ShouldBeNil)
+			So(obj.Name, ShouldEqual, "water")
+		}, []file{{"img", "a.png", png}, {"doc", "b.txt", text}}, map[string]string{"name": "water"})
+		So(resp.Code, ShouldEqual, http.StatusOK)
+		So(sinks[0].Bytes(), ShouldResemble, png)
+		So(sinks[1].String(), ShouldEqual, "hello")
+	})
+
+	Convey("skip files by nil Sink", t, func() {
+		serveUpload(func(ctx *Context) {
+			files, err := ctx.StreamUploads(func(p *UploadPart) (Sink, error) {
+				if p.FormName() == "doc" {
+					return nil, nil
+				}
+				return &MemorySink{}, nil
+			})
+			So(err, ShouldBeNil)
+			So(files, ShouldHaveLength, 1)
+			So(files[0].Field, ShouldEqual, "img")
+		}, []file{{"doc", "b.txt", text}, {"img", "a.png", png}}, nil)
+	})
+
+	Convey("disk sink", t, func() {
+		dir := t.TempDir()
+		sink := func(p *UploadPart) (Sink, error) {
+			return NewDiskSink(filepath.Join(dir, filepath.Base(p.FileName())))
+		}
+
+		serveUpload(func(ctx *Context) {
+			files, err := ctx.StreamUploads(sink)
+			So(err, ShouldBeNil)
+			So(files[0].Sink.(*DiskSink).Path, ShouldEqual, filepath.Join(dir, "a.png"))
+		}, []file{{"img", "a.png", png}}, nil)
+
+		data, err := os.ReadFile(filepath.Join(dir, "a.png"))
+		So(err, ShouldBeNil)
+		So(data, ShouldResemble, png)
+		os.Remove(filepath.Join(dir, "a.png"))
+
+		// the second file fails, so the first is removed after committed
+		resp := serveUpload(func(ctx *Context) {
+			_, err := ctx.StreamUploads(sink, UploadMaxFileSize(100))
+			So(err, ShouldNotBeNil)
+		}, []file{{"doc", "b.txt", text}, {"img", "a.png", png}}, nil)
+		So(resp.Code, ShouldEqual, http.StatusRequestEntityTooLarge)
+
+		entries, _ := os.ReadDir(dir)
+		So(entries, ShouldBeEmpty)
+
+		// the existing file is neither overwritten nor removed
+		os.WriteFile(filepath.Join(dir, "a.png"), text, 0o644)
+		serveUpload(func(ctx *Context) {
+			_, err := ctx.StreamUploads(sink)
+			So(errors.Is(err, fs.ErrExist), ShouldBeTrue)
+		}, []file{{"doc", "b.txt", text}, {"img", "a.png", png}}, nil)
+
+		data, err = os.ReadFile(filepath.Join(dir, "a.png"))
+		So(err, ShouldBeNil)
+		So(data, ShouldResemble, text)
+		entries, _ = os.ReadDir(dir)
+		So(entries, ShouldHaveLength, 1)
+	})
+
+	Convey("limits", t, func() {
+		resp := serveUpload(func(ctx *Context) {
+			_, err := ctx.StreamUploads(func(p *UploadPart) (Sink, error) {
+				return &MemorySink{}, nil
+			}, UploadMaxFileSize(100))
+
+			var fe *FileTooLargeError
+			So(errors.As(err, &fe), ShouldBeTrue)
+			So(fe.Filename, ShouldEqual, "a.png")
+			So(fe.Limit, ShouldEqual, 100)
+		}, []file{{"img", "a.png", png}}, nil)
+		So(resp.Code, ShouldEqual, http.StatusRequestEntityTooLarge)
+
+		// exactly the limit
+		serveUpload(func(ctx *Context) {
+			files, err := ctx.StreamUploads(func(p *UploadPart) (Sink, error) {
+				return &MemorySink{}, nil
+			}, UploadMaxFileSize(int64(len(png))))
+			So(err, ShouldBeNil)
+			So(files, ShouldHaveLength, 1)
+		}, []file{{"img", "a.png", png}}, nil)
+
+		// the names and header of a part are charged to the total
+		total := int64(len(png) + len("img") + len("a.png") + uploadPartOverhead)
+		serveUpload(func(ctx *Context) {
+			files, err := ctx.StreamUploads(func(p *UploadPart) (Sink, error) {
+				return &MemorySink{}, nil
+			}, UploadMaxTotalSize(total))
+			So(err, ShouldBeNil)
+			So(files, ShouldHaveLength, 1)
+		}, []file{{"img", "a.png", png}}, nil)
+
+		resp = serveUpload(func(ctx *Context) {
+			_, err := ctx.StreamUploads(func(p *UploadPart) (Sink, error) {
+				return &MemorySink{}, nil
+			}, UploadMaxFileSize(int64(len(png))), UploadMaxTotalSize(total))
+			So(err, ShouldResemble, &binding.BodyTooLargeError{Limit: total})
+		}, []file{{"img", "a.png", png}, {"doc", "b.txt", text}}, nil)
+		So(resp.Code, ShouldEqual, http.StatusRequestEntityTooLarge)
+
+		fields := make(map[string]string)
+		for i := 0; i <= maxUploadParts; i++ {
+			fields[strconv.Itoa(i)] = ""
+		}
+		resp = serveUpload(func(ctx *Context) {
+			_, err := ctx.StreamUploads(func(p *UploadPart) (Sink, error) {
+				return &MemorySink{}, nil
+			})
+			So(err, ShouldNotBeNil)
+		}, nil, fields)
+		So(resp.Code, ShouldEqual, http.StatusRequestEntityTooLarge)
+
+		// empty values with long names
+		fields = make(map[string]string)
+		for i := 0; i < 500; i++ {
+			fields[strconv.Itoa(i)+strings.Repeat("k", 25<<10)] = ""
+		}
+		resp = serveUpload(func(ctx *Context) {
+			_, err := ctx.StreamUploads(func(p *UploadPart) (Sink, error) {
+				return &MemorySink{}, nil
+			})
+			So(err, ShouldHaveSameTypeAs, &binding.BodyTooLargeError{})
+		}, nil, fields)
+		So(resp.Code, ShouldEqual, http.StatusRequestEntityTooLarge)
+	})
+
+	Convey("allowed types", t, func() {
+		var sinks []*MemorySink
+		resp := serveUpload(func(ctx *Context) {
+			_, err := ctx.StreamUploads(func(p *UploadPart) (Sink, error) {
+				s := &MemorySink{}
+				sinks = append(sinks, s)
+				return s, nil
+			}, UploadAllowTypes("image/*"))
+
+			var te *FileTypeError
+			So(errors.As(err, &te), ShouldBeTrue)
+			So(te.ContentType, ShouldStartWith, "text/plain")
+		}, []file{{"img", "a.png", png}, {"doc", "b.txt", text}}, nil)
+		So(resp.Code, ShouldEqual, http.StatusUnsupportedMediaType)
+		So(sinks, ShouldHaveLength, 1)
+		So(sinks[0].Len(), ShouldEqual, 0) // aborted
+
+		opts := uploadOptions{allowTypes: []string{"image/png", "application/*"}}
+		So(opts.allowed("image/png"), ShouldBeTrue)
+		So(opts.allowed("application/pdf"), ShouldBeTrue)
+		So(opts.allowed("image/gif"), ShouldBeFalse)
+	})
+
+	Convey("not multipart", t, func() {
+		e := newTestEngine("POST", "/upload", func(ctx *Context) {
+			_, err := ctx.StreamUploads(func(p *UploadPart) (Sink, error) {
+				return &MemorySink{}, nil
+			})
+			So(err, ShouldHaveSameTypeAs, &binding.UnsupportedMediaTypeError{})
+		})
+
+		resp := serve(e, "POST", "/upload", `{}`, HeaderContentType, MIMEApplicationJSON)
+		So(resp.Code, ShouldEqual, http.StatusUnsupportedMediaType)
+	})
+}
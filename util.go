@@ -48,10 +48,7 @@ func requestRealIp(req *http.Request) string {
 			return ips[0]
 		}
 
-		ip = req.RemoteAddr
-		if i := strings.LastIndex(ip, ":"); i > -1 {
-			ip = ip[:i]
-		}
+		ip = remoteIP(req.RemoteAddr)
 	}
 
 	return ip
@@ -103,6 +100,12 @@ func filterFlags(content string) string {
 	return content
 }
 
+// isStructPtr reports whether obj is a pointer to struct.
+func isStructPtr(obj interface{}) bool {
+	t := reflect.TypeOf(obj)
+	return t != nil && t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct
+}
+
 func nameOfFunction(f interface{}) string {
 	return runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
 }
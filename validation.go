@@ -0,0 +1,32 @@
+package water
+
+import (
+	"github.com/meilihao/water/binding"
+)
+
+// AbortValidation replies 422 with the binding.FieldErrors of err translated
+// by tr, default binding.DefaultTranslator, and returns true if err is a
+// validation error of obj.
+//
+//	if err := ctx.Bind(&req); err != nil {
+//		if !ctx.AbortValidation(&req, err, binding.Chinese) {
+//			ctx.BadRequest()
+//		}
+//		return
+//	}
+func (ctx *Context) AbortValidation(obj interface{}, err error, tr ...binding.Translator) bool {
+	var t binding.Translator
+	if len(tr) > 0 {
+		t = tr[0]
+	}
+
+	es := binding.NewFieldErrors(obj, err, t)
+	if len(es) == 0 {
+		return false
+	}
+
+	body := errorBody{Error: "validation failed", Fields: es}
+	ctx.Negotiate(es.StatusCode(), Offers{JSON: body, XML: body})
+
+	return true
+}
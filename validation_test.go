@@ -0,0 +1,113 @@
+package water
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/meilihao/water/binding"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAbortValidation(t *testing.T) {
+	type item struct {
+		Name string `json:"name" binding:"required"`
+	}
+	type order struct {
+		Items []item `json:"items" binding:"dive"`
+	}
+
+	e := newTestEngine("POST", "/", func(ctx *Context) {
+		var o order
+		if err := ctx.Bind(&o); err != nil {
+			if !ctx.AbortValidation(&o, err, binding.Chinese) {
+				ctx.BadRequest()
+			}
+			return
+		}
+		ctx.String(200, "ok")
+	})
+	serveAccept := func(body string, accept string) *httptest.ResponseRecorder {
+		return serve(e, "POST", "/", body, HeaderContentType, MIMEApplicationJSON, HeaderAccept, accept)
+	}
+
+	Convey("validation error", t, func() {
+		resp := serveAccept(`{"items":[{"name":"a"},{}]}`, MIMEApplicationJSON)
+		So(resp.Code, ShouldEqual, http.StatusUnprocessableEntity)
+		So(resp.Body.String(), ShouldEqual, `{"error":"validation failed","fields":[{"field":"items[1].name","rule":"required","value":"","message":"items[1].name为必填字段"}]}`+"\n")
+
+		resp = serveAccept(`{"items":[{}]}`, MIMEApplicationXML)
+		So(resp.Body.String(), ShouldContainSubstring, `<field><field>items[0].name</field><rule>required</rule>`)
+	})
+
+	Convey("other error", t, func() {
+		resp := serveAccept(`{"items":`, MIMEApplicationJSON)
+		So(resp.Code, ShouldEqual, http.StatusBadRequest)
+
+		So(serveAccept(`{"items":[{"name":"a"}]}`, "").Body.String(), ShouldEqual, "ok")
+	})
+}
+
+func TestWithValidator(t *testing.T) {
+	type req struct {
+		Name string `json:"name" form:"name" binding:"required,admin_name"`
+	}
+
+	r := NewRouter()
+	r.POST("/", func(ctx *Context) {
+		var v req
+		if err := ctx.Bind(&v); err != nil {
+			ctx.String(400, err.Error())
+			return
+		}
+		ctx.String(200, v.Name)
+	})
+
+	admin := binding.NewValidator("binding")
+	admin.RegisterRule("admin_name", func(fl validator.FieldLevel) bool {
+		return strings.HasPrefix(fl.Field().String(), "admin")
+	})
+	public := binding.NewValidator("binding")
+	public.RegisterRule("admin_name", func(fl validator.FieldLevel) bool {
+		return true
+	})
+
+	serveJSON := func(e *Engine, body string) *httptest.ResponseRecorder {
+		return serve(e, "POST", "/", body, HeaderContentType, MIMEApplicationJSON)
+	}
+
+	Convey("two engines with different rules", t, func() {
+		ea := r.Handler(WithValidator(admin))
+		ep := r.Handler(WithValidator(public))
+
+		So(serveJSON(ea, `{"name":"bob"}`).Code, ShouldEqual, 400)
+		So(serveJSON(ea, `{"name":"admin1"}`).Body.String(), ShouldEqual, "admin1")
+		So(serveJSON(ep, `{"name":"bob"}`).Body.String(), ShouldEqual, "bob")
+		So(serveJSON(ep, `{}`).Code, ShouldEqual, 400)
+	})
+
+	Convey("a Bindinger which is not a Decoder", t, func() {
+		e := newTestEngine("POST", "/", func(ctx *Context) {
+			var v req
+			if err := ctx.BindWith(&v, jsonBindOnly{}); err != nil {
+				ctx.String(400, err.Error())
+				return
+			}
+			ctx.String(200, v.Name)
+		}, WithValidator(admin))
+
+		So(serveJSON(e, `{"name":"bob"}`).Code, ShouldEqual, 400)
+		So(serveJSON(e, `{"name":"admin1"}`).Body.String(), ShouldEqual, "admin1")
+	})
+}
+
+// jsonBindOnly binds JSON without validation, and is not a binding.Decoder.
+type jsonBindOnly struct{}
+
+func (jsonBindOnly) Name() string { return "json-bind-only" }
+
+func (jsonBindOnly) Bind(req *http.Request, obj interface{}) error {
+	return json.NewDecoder(req.Body).Decode(obj)
+}
@@ -0,0 +1,335 @@
+package water
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/meilihao/water/binding"
+)
+
+// ValueErrors are all invalid values found by ValueParser.
+type ValueErrors []*binding.FieldError
+
+func (es ValueErrors) Error() string {
+	return binding.FieldErrors(es).Error()
+}
+
+// StatusCode returns 400.
+func (es ValueErrors) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// ValueRule checks the parsed value, see Min, Max and Required.
+type ValueRule func(*valueRules)
+
+type valueRules struct {
+	min, max *float64
+	required bool
+}
+
+// Min is the min value of number, or the min length of string.
+func Min(n float64) ValueRule {
+	return func(r *valueRules) {
+		r.min = &n
+	}
+}
+
+// Max is the max value of number, or the max length of string.
+func Max(n float64) ValueRule {
+	return func(r *valueRules) {
+		r.max = &n
+	}
+}
+
+// Required reports the missing value as an error instead of using the default.
+func Required() ValueRule {
+	return func(r *valueRules) {
+		r.required = true
+	}
+}
+
+// ValueParser parses the values of a source, and accumulates the errors
+// instead of returning zero, so all invalid values can be reported at once.
+//
+//	q := ctx.QueryParser()
+//	limit := q.Int("limit", 10, water.Min(1), water.Max(100))
+//	since := q.Time("since", time.RFC3339)
+//	if q.Abort() {
+//		return
+//	}
+type ValueParser struct {
+	ctx    *Context
+	source string
+	lookup func(name string) (string, bool)
+	errs   ValueErrors
+	// bodyErr is the error of reading the body, see FormParser.
+	bodyErr error
+}
+
+// QueryParser parses the URL query.
+func (ctx *Context) QueryParser() *ValueParser {
+	query := ctx.Request.URL.Query()
+
+	return ctx.newValueParser("query", func(name string) (string, bool) {
+		vs, ok := query[name]
+		if !ok || len(vs) == 0 {
+			return "", false
+		}
+		return strings.TrimSpace(vs[0]), true
+	})
+}
+
+// FormParser parses the form of request body. A malformed body is reported
+// by Err as a FieldError of rule "form", a too large body as
+// *binding.BodyTooLargeError.
+func (ctx *Context) FormParser() *ValueParser {
+	p := ctx.newValueParser("form", func(name string) (string, bool) {
+		vs, ok := ctx.Request.PostForm[name]
+		if !ok || len(vs) == 0 {
+			return "", false
+		}
+		return strings.TrimSpace(vs[0]), true
+	})
+
+	if err := ctx.parseForm(); err != nil {
+		p.bodyErr = err
+		p.errs = append(p.errs, &binding.FieldError{
+			Source:  p.source,
+			Rule:    "form",
+			Message: err.Error(),
+		})
+	}
+
+	return p
+}
+
+// ParamParser parses the URL params.
+func (ctx *Context) ParamParser() *ValueParser {
+	return ctx.newValueParser("param", func(name string) (string, bool) {
+		v, ok := ctx.Params[name]
+		return v, ok
+	})
+}
+
+// CookieParser parses the cookies.
+func (ctx *Context) CookieParser() *ValueParser {
+	return ctx.newValueParser("cookie", func(name string) (string, bool) {
+		c, err := ctx.Request.Cookie(name)
+		if err != nil {
+			return "", false
+		}
+		return c.Value, true
+	})
+}
+
+func (ctx *Context) newValueParser(source string, lookup func(string) (string, bool)) *ValueParser {
+	return &ValueParser{
+		ctx:    ctx,
+		source: source,
+		lookup: lookup,
+	}
+}
+
+// fail records an invalid value of name failed rule, msg follows the name.
+func (p *ValueParser) fail(name, value, rule, param, msg string) {
+	p.errs = append(p.errs, &binding.FieldError{
+		Source:  p.source,
+		Field:   name,
+		Rule:    rule,
+		Param:   param,
+		Value:   value,
+		Message: name + " " + msg,
+	})
+}
+
+// get returns the raw value, false if it is missing or empty.
+func (p *ValueParser) get(name string, rules []ValueRule) (string, *valueRules, bool) {
+	r := &valueRules{}
+	for _, rule := range rules {
+		rule(r)
+	}
+
+	v, ok := p.lookup(name)
+	if !ok || v == "" {
+		if r.required {
+			p.fail(name, v, "required", "", "is required")
+		}
+		return "", r, false
+	}
+
+	return v, r, true
+}
+
+// checkRange checks n by Min and Max, n is the length of value if isLen.
+func (p *ValueParser) checkRange(name, value string, n float64, r *valueRules, isLen bool) bool {
+	if r.min != nil && n < *r.min {
+		param := fmt.Sprint(*r.min)
+		if isLen {
+			p.fail(name, value, "min", param, "must be at least "+param+" characters long")
+		} else {
+			p.fail(name, value, "min", param, "must be "+param+" or greater")
+		}
+		return false
+	}
+	if r.max != nil && n > *r.max {
+		param := fmt.Sprint(*r.max)
+		if isLen {
+			p.fail(name, value, "max", param, "must be at most "+param+" characters long")
+		} else {
+			p.fail(name, value, "max", param, "must be "+param+" or less")
+		}
+		return false
+	}
+
+	return true
+}
+
+// String returns the value of name, def if missing.
+func (p *ValueParser) String(name, def string, rules ...ValueRule) string {
+	v, r, ok := p.get(name, rules)
+	if !ok || !p.checkRange(name, v, float64(len([]rune(v))), r, true) {
+		return def
+	}
+
+	return v
+}
+
+// Int returns the value of name, def if missing or invalid.
+func (p *ValueParser) Int(name string, def int, rules ...ValueRule) int {
+	return int(p.parseInt(name, int64(def), strconv.IntSize, rules))
+}
+
+// Int64 returns the value of name, def if missing or invalid.
+func (p *ValueParser) Int64(name string, def int64, rules ...ValueRule) int64 {
+	return p.parseInt(name, def, 64, rules)
+}
+
+func (p *ValueParser) parseInt(name string, def int64, bitSize int, rules []ValueRule) int64 {
+	v, r, ok := p.get(name, rules)
+	if !ok {
+		return def
+	}
+
+	n, err := strconv.ParseInt(v, 10, bitSize)
+	if err != nil {
+		p.fail(name, v, "int", "", "must be an integer")
+		return def
+	}
+	if !p.checkRange(name, v, float64(n), r, false) {
+		return def
+	}
+
+	return n
+}
+
+// Uint returns the value of name, def if missing or invalid.
+func (p *ValueParser) Uint(name string, def uint, rules ...ValueRule) uint {
+	return uint(p.parseUint(name, uint64(def), strconv.IntSize, rules))
+}
+
+// Uint64 returns the value of name, def if missing or invalid.
+func (p *ValueParser) Uint64(name string, def uint64, rules ...ValueRule) uint64 {
+	return p.parseUint(name, def, 64, rules)
+}
+
+func (p *ValueParser) parseUint(name string, def uint64, bitSize int, rules []ValueRule) uint64 {
+	v, r, ok := p.get(name, rules)
+	if !ok {
+		return def
+	}
+
+	n, err := strconv.ParseUint(v, 10, bitSize)
+	if err != nil {
+		p.fail(name, v, "uint", "", "must be a non-negative integer")
+		return def
+	}
+	if !p.checkRange(name, v, float64(n), r, false) {
+		return def
+	}
+
+	return n
+}
+
+// Float64 returns the value of name, def if missing or invalid.
+func (p *ValueParser) Float64(name string, def float64, rules ...ValueRule) float64 {
+	v, r, ok := p.get(name, rules)
+	if !ok {
+		return def
+	}
+
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		p.fail(name, v, "number", "", "must be a number")
+		return def
+	}
+	if !p.checkRange(name, v, n, r, false) {
+		return def
+	}
+
+	return n
+}
+
+// Bool returns the value of name, def if missing or invalid.
+func (p *ValueParser) Bool(name string, def bool, rules ...ValueRule) bool {
+	v, _, ok := p.get(name, rules)
+	if !ok {
+		return def
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		p.fail(name, v, "bool", "", "must be a boolean")
+		return def
+	}
+
+	return b
+}
+
+// Time returns the value of name parsed by layout, zero time if missing or invalid.
+func (p *ValueParser) Time(name, layout string, rules ...ValueRule) time.Time {
+	v, _, ok := p.get(name, rules)
+	if !ok {
+		return time.Time{}
+	}
+
+	t, err := time.Parse(layout, v)
+	if err != nil {
+		p.fail(name, v, "time", layout, "must be a time in layout "+layout)
+		return time.Time{}
+	}
+
+	return t
+}
+
+// Err returns ValueErrors if any value is invalid, otherwise nil.
+func (p *ValueParser) Err() error {
+	if len(p.errs) == 0 {
+		return nil
+	}
+	if errors.As(p.bodyErr, new(*binding.BodyTooLargeError)) {
+		return p.bodyErr
+	}
+
+	return p.errs
+}
+
+// Abort replies 400 with all invalid values and returns true if any value is
+// invalid, or 413 if the body is too large.
+func (p *ValueParser) Abort() bool {
+	if len(p.errs) == 0 {
+		return false
+	}
+	if errors.As(p.bodyErr, new(*binding.BodyTooLargeError)) {
+		p.ctx.abortOnBodyError(p.bodyErr)
+		return true
+	}
+
+	body := errorBody{Error: "invalid parameters", Fields: p.errs}
+	p.ctx.Negotiate(http.StatusBadRequest, Offers{JSON: body, XML: body})
+
+	return true
+}
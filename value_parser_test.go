@@ -0,0 +1,95 @@
+package water
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/meilihao/water/binding"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestValueParser(t *testing.T) {
+	serveParser := func(h func(ctx *Context), method, url string, body string) *httptest.ResponseRecorder {
+		return serve(newTestEngine(method, "/<id>", h), method, url, body,
+			HeaderContentType, binding.MIMEPOSTForm, "Cookie", "n=x")
+	}
+
+	Convey("valid and default values", t, func() {
+		serveParser(func(ctx *Context) {
+			q := ctx.QueryParser()
+			So(q.Int("limit", 10, Min(1), Max(100)), ShouldEqual, 20)
+			So(q.Int("offset", 5), ShouldEqual, 5)
+			So(q.Bool("desc", false), ShouldBeTrue)
+			So(q.Float64("f", 0), ShouldEqual, 1.5)
+			So(q.String("name", "", Max(3)), ShouldEqual, "水ab")
+			So(q.Time("since", time.RFC3339).Year(), ShouldEqual, 2020)
+			So(q.Err(), ShouldBeNil)
+			So(q.Abort(), ShouldBeFalse)
+
+			So(ctx.ParamParser().Uint("id", 0), ShouldEqual, 7)
+		}, "GET", "/7?limit=20&desc=true&f=1.5&name=水ab&since=2020-01-02T00:00:00Z&offset=", "")
+	})
+
+	Convey("invalid values are reported at once", t, func() {
+		resp := serveParser(func(ctx *Context) {
+			q := ctx.QueryParser()
+			So(q.Int("limit", 10, Min(1), Max(100)), ShouldEqual, 10)
+			So(q.Int("page", 1, Min(1)), ShouldEqual, 1)
+			So(q.Time("since", time.RFC3339).IsZero(), ShouldBeTrue)
+			q.String("q", "", Required())
+
+			err := q.Err()
+			var es ValueErrors
+			So(errors.As(err, &es), ShouldBeTrue)
+			So(es, ShouldHaveLength, 4)
+			So(statusOfError(err), ShouldEqual, http.StatusBadRequest)
+			So(*es[1], ShouldResemble, binding.FieldError{Source: "query", Field: "page", Rule: "min", Param: "1", Value: "0", Message: "page must be 1 or greater"})
+
+			So(q.Abort(), ShouldBeTrue)
+		}, "GET", "/1?limit=abc&page=0&since=yesterday", "")
+
+		So(resp.Code, ShouldEqual, http.StatusBadRequest)
+		So(resp.Body.String(), ShouldStartWith, `{"error":"invalid parameters","fields":[{"source":"query","field":"limit","rule":"int","value":"abc","message":"limit must be an integer"},`)
+		So(resp.Body.String(), ShouldContainSubstring, `"field":"q","rule":"required","value":"","message":"q is required"`)
+	})
+
+	Convey("form, param and cookie", t, func() {
+		serveParser(func(ctx *Context) {
+			f := ctx.FormParser()
+			So(f.Int("a", 0), ShouldEqual, 1)
+			So(f.Int("limit", 0), ShouldEqual, 0) // only in query
+			So(f.Err(), ShouldBeNil)
+
+			p := ctx.ParamParser()
+			p.Int("id", 0)
+			So(p.Err().Error(), ShouldEqual, "id must be an integer")
+
+			c := ctx.CookieParser()
+			c.Int64("n", 0)
+			So(c.Err().Error(), ShouldEqual, "n must be an integer")
+		}, "POST", "/abc?limit=1", "a=1")
+	})
+	Convey("malformed form body", t, func() {
+		resp := serveParser(func(ctx *Context) {
+			f := ctx.FormParser()
+			So(f.Int("a", 0), ShouldEqual, 0)
+
+			var es ValueErrors
+			So(errors.As(f.Err(), &es), ShouldBeTrue)
+			So(es[0].Rule, ShouldEqual, "form")
+			So(f.Abort(), ShouldBeTrue)
+		}, "POST", "/1", "a=%zz")
+		So(resp.Code, ShouldEqual, http.StatusBadRequest)
+		So(resp.Body.String(), ShouldContainSubstring, `"source":"form","field":"","rule":"form"`)
+
+		resp = serve(newTestEngine("POST", "/", func(ctx *Context) {
+			f := ctx.FormParser()
+			So(f.Err(), ShouldHaveSameTypeAs, &binding.BodyTooLargeError{})
+			So(f.Abort(), ShouldBeTrue)
+		}, WithMaxBodyBytes(4)), "POST", "/", "a=123456", HeaderContentType, binding.MIMEPOSTForm)
+		So(resp.Code, ShouldEqual, http.StatusRequestEntityTooLarge)
+	})
+}
@@ -0,0 +1,179 @@
+package water
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// from RFC 6455 1.3
+const webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+var (
+	// DefaultWebSocketReadLimit is the max bytes of a received message if WebSocketOptions.ReadLimit is 0
+	DefaultWebSocketReadLimit int64 = 32 << 20 // 32MB
+
+	ErrWebSocketHandshake = errors.New("water: websocket: bad handshake")
+	ErrWebSocketOrigin    = errors.New("water: websocket: origin not allowed")
+)
+
+// WebSocketOptions is the options of ctx.UpgradeWebSocket.
+type WebSocketOptions struct {
+	// Subprotocols are the supported subprotocols in order of preference,
+	// the first one offered by client is selected.
+	Subprotocols []string
+
+	// CheckOrigin returns true if the Origin header is acceptable.
+	// nil means the Origin must be absent or the same as the request Host.
+	CheckOrigin func(ctx *Context) bool
+
+	// ReadLimit is the max bytes of a received message, 0 means DefaultWebSocketReadLimit.
+	ReadLimit int64
+
+	// MaxFrameSize splits the sent message into frames of at most
+	// MaxFrameSize bytes, 0 means not split.
+	MaxFrameSize int
+}
+
+// UpgradeWebSocket does the RFC 6455 opening handshake and returns the connection.
+// The response is replied and error is returned if the handshake fails:
+// 400 for an invalid request, 426 for an unsupported version and 403 for a disallowed origin.
+// The connection should be closed by the caller.
+func (ctx *Context) UpgradeWebSocket(opts *WebSocketOptions) (*WebSocketConn, error) {
+	if opts == nil {
+		opts = &WebSocketOptions{}
+	}
+
+	req := ctx.Request
+	if req.Method != http.MethodGet ||
+		!headerContainsToken(req.Header, HeaderConnection, "upgrade") ||
+		!headerContainsToken(req.Header, HeaderUpgrade, "websocket") {
+		ctx.Abort(http.StatusBadRequest)
+		return nil, ErrWebSocketHandshake
+	}
+
+	if req.Header.Get(HeaderSecWebSocketVersion) != "13" {
+		ctx.Header().Set(HeaderSecWebSocketVersion, "13")
+		ctx.Abort(http.StatusUpgradeRequired)
+		return nil, ErrWebSocketHandshake
+	}
+
+	key := strings.TrimSpace(req.Header.Get(HeaderSecWebSocketKey))
+	if k, err := base64.StdEncoding.DecodeString(key); err != nil || len(k) != 16 {
+		ctx.Abort(http.StatusBadRequest)
+		return nil, ErrWebSocketHandshake
+	}
+
+	checkOrigin := opts.CheckOrigin
+	if checkOrigin == nil {
+		checkOrigin = sameOrigin
+	}
+	if !checkOrigin(ctx) {
+		ctx.Abort(http.StatusForbidden)
+		return nil, ErrWebSocketOrigin
+	}
+
+	protocol := selectSubprotocol(req.Header, opts.Subprotocols)
+
+	netConn, brw, err := http.NewResponseController(ctx.ResponseWriter).Hijack()
+	if err != nil {
+		if !ctx.Written() {
+			ctx.Abort(http.StatusInternalServerError)
+		}
+		return nil, err
+	}
+
+	// keep the headers set before, e.g. Set-Cookie
+	h := ctx.Header().Clone()
+	for _, k := range []string{HeaderContentType, HeaderSecWebSocketExtensions} {
+		h.Del(k)
+	}
+	h.Set(HeaderUpgrade, "websocket")
+	h.Set(HeaderConnection, "Upgrade")
+	h.Set(HeaderSecWebSocketAccept, webSocketAccept(key))
+	if protocol != "" {
+		h.Set(HeaderSecWebSocketProtocol, protocol)
+	}
+
+	ctx.rw.status = http.StatusSwitchingProtocols
+	ctx.rw.writeAt = time.Now()
+
+	brw.Writer.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+	h.Write(brw.Writer)
+	brw.Writer.WriteString("\r\n")
+	if err = brw.Writer.Flush(); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+
+	// clear the deadlines set by http.Server
+	netConn.SetDeadline(time.Time{})
+
+	return newWebSocketConn(netConn, brw, protocol, opts), nil
+}
+
+// webSocketAccept computes the Sec-WebSocket-Accept from Sec-WebSocket-Key.
+func webSocketAccept(key string) string {
+	sum := sha1.Sum([]byte(key + webSocketGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// sameOrigin allows no Origin or the Origin whose host equals the request Host.
+func sameOrigin(ctx *Context) bool {
+	origin := ctx.Request.Header.Get(HeaderOrigin)
+	if origin == "" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+
+	return strings.EqualFold(u.Host, ctx.Request.Host)
+}
+
+func selectSubprotocol(h http.Header, supported []string) string {
+	if len(supported) == 0 {
+		return ""
+	}
+
+	offered := headerTokens(h, HeaderSecWebSocketProtocol)
+	for _, s := range supported {
+		for _, o := range offered {
+			if s == o {
+				return s
+			}
+		}
+	}
+
+	return ""
+}
+
+// headerTokens returns the comma-separated tokens of header name.
+func headerTokens(h http.Header, name string) []string {
+	var ls []string
+	for _, v := range h.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				ls = append(ls, t)
+			}
+		}
+	}
+
+	return ls
+}
+
+func headerContainsToken(h http.Header, name, token string) bool {
+	for _, t := range headerTokens(h, name) {
+		if strings.EqualFold(t, token) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,429 @@
+package water
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// WebSocketMessageType is the opcode of a WebSocket frame.
+type WebSocketMessageType int
+
+const (
+	webSocketContinuation WebSocketMessageType = 0
+
+	WebSocketText   WebSocketMessageType = 1
+	WebSocketBinary WebSocketMessageType = 2
+	WebSocketClose  WebSocketMessageType = 8
+	WebSocketPing   WebSocketMessageType = 9
+	WebSocketPong   WebSocketMessageType = 10
+)
+
+// WebSocket close codes, see RFC 6455 7.4.1
+const (
+	WebSocketCloseNormal          = 1000
+	WebSocketCloseGoingAway       = 1001
+	WebSocketCloseProtocolError   = 1002
+	WebSocketCloseUnsupportedData = 1003
+	WebSocketCloseNoStatus        = 1005
+	WebSocketCloseInvalidPayload  = 1007
+	WebSocketClosePolicyViolation = 1008
+	WebSocketCloseTooBig          = 1009
+	WebSocketCloseInternalError   = 1011
+)
+
+const maxControlPayload = 125
+
+var (
+	ErrWebSocketClosed    = errors.New("water: websocket: connection closed")
+	ErrWebSocketReadLimit = errors.New("water: websocket: read limit exceeded")
+)
+
+// WebSocketCloseError is returned by ReadMessage when a close frame is received.
+type WebSocketCloseError struct {
+	Code int
+	Text string
+}
+
+func (e *WebSocketCloseError) Error() string {
+	return fmt.Sprintf("water: websocket: close %d %s", e.Code, e.Text)
+}
+
+// WebSocketConn is a WebSocket connection.
+// ReadMessage should be called by one goroutine at a time, the write methods
+// are safe for concurrent use.
+type WebSocketConn struct {
+	conn     net.Conn
+	br       *bufio.Reader
+	protocol string
+
+	readLimit   int64
+	readErr     error
+	pingHandler func([]byte) error
+	pongHandler func([]byte) error
+
+	wlock        sync.Mutex
+	bw           *bufio.Writer
+	maxFrameSize int
+	closeSent    bool
+}
+
+func newWebSocketConn(conn net.Conn, brw *bufio.ReadWriter, protocol string, opts *WebSocketOptions) *WebSocketConn {
+	c := &WebSocketConn{
+		conn:         conn,
+		br:           brw.Reader,
+		bw:           brw.Writer,
+		protocol:     protocol,
+		readLimit:    opts.ReadLimit,
+		maxFrameSize: opts.MaxFrameSize,
+	}
+	if c.readLimit <= 0 {
+		c.readLimit = DefaultWebSocketReadLimit
+	}
+	c.pingHandler = func(data []byte) error {
+		return c.writeFrame(WebSocketPong, data)
+	}
+
+	return c
+}
+
+// Subprotocol returns the negotiated subprotocol, "" if none.
+func (c *WebSocketConn) Subprotocol() string {
+	return c.protocol
+}
+
+// NetConn returns the underlying connection.
+func (c *WebSocketConn) NetConn() net.Conn {
+	return c.conn
+}
+
+func (c *WebSocketConn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// SetReadLimit sets the max bytes of a received message.
+func (c *WebSocketConn) SetReadLimit(n int64) {
+	c.readLimit = n
+}
+
+func (c *WebSocketConn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+func (c *WebSocketConn) SetWriteDeadline(t time.Time) error {
+	return c.conn.SetWriteDeadline(t)
+}
+
+// SetPingHandler sets the handler for the received ping, default replies a pong.
+func (c *WebSocketConn) SetPingHandler(h func(data []byte) error) {
+	c.pingHandler = h
+}
+
+// SetPongHandler sets the handler for the received pong, default ignores it.
+func (c *WebSocketConn) SetPongHandler(h func(data []byte) error) {
+	c.pongHandler = h
+}
+
+// ReadMessage returns the next text or binary message, the fragments are
+// joined, and the control frames are handled meanwhile.
+// It returns *WebSocketCloseError after the close frame is received and echoed.
+func (c *WebSocketConn) ReadMessage() (WebSocketMessageType, []byte, error) {
+	if c.readErr != nil {
+		return 0, nil, c.readErr
+	}
+
+	typ, data, err := c.readMessage()
+	if err != nil {
+		c.readErr = err
+	}
+	return typ, data, err
+}
+
+func (c *WebSocketConn) readMessage() (WebSocketMessageType, []byte, error) {
+	var (
+		typ        WebSocketMessageType
+		msg        []byte
+		fragmented bool
+	)
+
+	for {
+		fin, opcode, payload, err := c.readFrame(int64(len(msg)))
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch opcode {
+		case WebSocketPing:
+			if c.pingHandler != nil {
+				if err = c.pingHandler(payload); err != nil {
+					return 0, nil, err
+				}
+			}
+			continue
+		case WebSocketPong:
+			if c.pongHandler != nil {
+				if err = c.pongHandler(payload); err != nil {
+					return 0, nil, err
+				}
+			}
+			continue
+		case WebSocketClose:
+			return 0, nil, c.handleClose(payload)
+		case WebSocketText, WebSocketBinary:
+			if fragmented {
+				return 0, nil, c.fail(WebSocketCloseProtocolError, "new message before the last fragment")
+			}
+			typ, msg, fragmented = opcode, payload, !fin
+		case webSocketContinuation:
+			if !fragmented {
+				return 0, nil, c.fail(WebSocketCloseProtocolError, "continuation without message")
+			}
+			msg = append(msg, payload...)
+			fragmented = !fin
+		}
+
+		if !fragmented {
+			if typ == WebSocketText && !utf8.Valid(msg) {
+				return 0, nil, c.fail(WebSocketCloseInvalidPayload, "invalid utf-8 text")
+			}
+			return typ, msg, nil
+		}
+	}
+}
+
+// readFrame reads and validates a frame, returns the unmasked payload.
+// read is the bytes of the fragments read before of the current message.
+func (c *WebSocketConn) readFrame(read int64) (bool, WebSocketMessageType, []byte, error) {
+	var h [8]byte
+	if _, err := io.ReadFull(c.br, h[:2]); err != nil {
+		return false, 0, nil, err
+	}
+
+	fin := h[0]&0x80 != 0
+	opcode := WebSocketMessageType(h[0] & 0x0f)
+	masked := h[1]&0x80 != 0
+	n := int64(h[1] & 0x7f)
+
+	if h[0]&0x70 != 0 {
+		return false, 0, nil, c.fail(WebSocketCloseProtocolError, "reserved bits set without extension")
+	}
+
+	isControl := false
+	switch opcode {
+	case webSocketContinuation, WebSocketText, WebSocketBinary:
+	case WebSocketClose, WebSocketPing, WebSocketPong:
+		isControl = true
+	default:
+		return false, 0, nil, c.fail(WebSocketCloseProtocolError, "reserved opcode")
+	}
+
+	if !masked {
+		return false, 0, nil, c.fail(WebSocketCloseProtocolError, "unmasked client frame")
+	}
+
+	switch n {
+	case 126:
+		if _, err := io.ReadFull(c.br, h[:2]); err != nil {
+			return false, 0, nil, err
+		}
+		n = int64(binary.BigEndian.Uint16(h[:2]))
+	case 127:
+		if _, err := io.ReadFull(c.br, h[:8]); err != nil {
+			return false, 0, nil, err
+		}
+		if h[0]&0x80 != 0 {
+			return false, 0, nil, c.fail(WebSocketCloseProtocolError, "invalid payload length")
+		}
+		n = int64(binary.BigEndian.Uint64(h[:8]))
+	}
+
+	if isControl && (!fin || n > maxControlPayload) {
+		return false, 0, nil, c.fail(WebSocketCloseProtocolError, "invalid control frame")
+	}
+	if !isControl && read+n > c.readLimit {
+		c.fail(WebSocketCloseTooBig, "message too big")
+		return false, 0, nil, ErrWebSocketReadLimit
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+
+	return fin, opcode, payload, nil
+}
+
+// handleClose echoes the close frame and closes the connection.
+func (c *WebSocketConn) handleClose(payload []byte) error {
+	e := &WebSocketCloseError{Code: WebSocketCloseNoStatus}
+
+	switch {
+	case len(payload) == 1:
+		return c.fail(WebSocketCloseProtocolError, "invalid close payload")
+	case len(payload) >= 2:
+		e.Code = int(binary.BigEndian.Uint16(payload))
+		e.Text = string(payload[2:])
+
+		if !validCloseCode(e.Code) {
+			return c.fail(WebSocketCloseProtocolError, "invalid close code")
+		}
+		if !utf8.ValidString(e.Text) {
+			return c.fail(WebSocketCloseInvalidPayload, "invalid utf-8 close reason")
+		}
+	}
+
+	if e.Code == WebSocketCloseNoStatus {
+		c.writeFrame(WebSocketClose, nil)
+	} else {
+		c.writeFrame(WebSocketClose, payload[:2])
+	}
+	c.conn.Close()
+
+	return e
+}
+
+func validCloseCode(code int) bool {
+	switch {
+	case code >= 1000 && code <= 1003:
+		return true
+	case code >= 1007 && code <= 1014:
+		return true
+	case code >= 3000 && code <= 4999:
+		return true
+	}
+	return false
+}
+
+// fail closes the connection with code for the invalid frame.
+func (c *WebSocketConn) fail(code int, reason string) error {
+	c.writeClose(code, reason)
+	c.conn.Close()
+
+	return fmt.Errorf("water: websocket: %s", reason)
+}
+
+// WriteMessage sends a text or binary message.
+func (c *WebSocketConn) WriteMessage(typ WebSocketMessageType, data []byte) error {
+	if typ != WebSocketText && typ != WebSocketBinary {
+		return fmt.Errorf("water: websocket: invalid message type %d", typ)
+	}
+
+	return c.writeFrame(typ, data)
+}
+
+// WriteText sends a text message.
+func (c *WebSocketConn) WriteText(s string) error {
+	return c.writeFrame(WebSocketText, []byte(s))
+}
+
+// Ping sends a ping, data should not be more than 125 bytes.
+func (c *WebSocketConn) Ping(data []byte) error {
+	if len(data) > maxControlPayload {
+		return errors.New("water: websocket: control frame too long")
+	}
+
+	return c.writeFrame(WebSocketPing, data)
+}
+
+// Close sends a close frame with WebSocketCloseNormal and closes the
+// connection without waiting for the echo.
+func (c *WebSocketConn) Close() error {
+	return c.CloseWith(WebSocketCloseNormal, "")
+}
+
+// CloseWith is the same as Close with code and reason.
+func (c *WebSocketConn) CloseWith(code int, reason string) error {
+	c.writeClose(code, reason)
+
+	return c.conn.Close()
+}
+
+func (c *WebSocketConn) writeClose(code int, reason string) error {
+	if len(reason) > maxControlPayload-2 {
+		reason = reason[:maxControlPayload-2]
+	}
+
+	payload := make([]byte, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	copy(payload[2:], reason)
+
+	return c.writeFrame(WebSocketClose, payload)
+}
+
+// writeFrame writes the unmasked frames of data, data message may be split by maxFrameSize.
+func (c *WebSocketConn) writeFrame(opcode WebSocketMessageType, data []byte) error {
+	c.wlock.Lock()
+	defer c.wlock.Unlock()
+
+	if c.closeSent {
+		return ErrWebSocketClosed
+	}
+	if opcode == WebSocketClose {
+		c.closeSent = true
+	}
+
+	first := true
+	for {
+		chunk := data
+		if c.maxFrameSize > 0 && opcode < WebSocketClose && len(chunk) > c.maxFrameSize {
+			chunk = data[:c.maxFrameSize]
+		}
+		data = data[len(chunk):]
+
+		b0 := byte(webSocketContinuation)
+		if first {
+			b0 = byte(opcode)
+		}
+		if len(data) == 0 {
+			b0 |= 0x80 // FIN
+		}
+		if err := c.writeFrameHeader(b0, len(chunk)); err != nil {
+			return err
+		}
+		if _, err := c.bw.Write(chunk); err != nil {
+			return err
+		}
+
+		if len(data) == 0 {
+			break
+		}
+		first = false
+	}
+
+	return c.bw.Flush()
+}
+
+func (c *WebSocketConn) writeFrameHeader(b0 byte, n int) error {
+	h := make([]byte, 2, 10)
+	h[0] = b0
+
+	switch {
+	case n <= 125:
+		h[1] = byte(n)
+	case n <= 0xffff:
+		h[1] = 126
+		h = h[:4]
+		binary.BigEndian.PutUint16(h[2:], uint16(n))
+	default:
+		h[1] = 127
+		h = h[:10]
+		binary.BigEndian.PutUint64(h[2:], uint64(n))
+	}
+
+	_, err := c.bw.Write(h)
+	return err
+}
@@ -0,0 +1,229 @@
+package water
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// wsTestClient is a minimal in-process WebSocket client
+type wsTestClient struct {
+	conn net.Conn
+	br   *bufio.Reader
+	resp *http.Response
+}
+
+func dialWebSocket(addr, path string, header http.Header) (*wsTestClient, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	req, _ := http.NewRequest("GET", "http://"+addr+path, nil)
+	req.Header.Set(HeaderConnection, "keep-alive, Upgrade")
+	req.Header.Set(HeaderUpgrade, "websocket")
+	req.Header.Set(HeaderSecWebSocketVersion, "13")
+	req.Header.Set(HeaderSecWebSocketKey, "dGhlIHNhbXBsZSBub25jZQ==")
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if err = req.Write(conn); err != nil {
+		return nil, err
+	}
+
+	c := &wsTestClient{conn: conn, br: bufio.NewReader(conn)}
+	c.resp, err = http.ReadResponse(c.br, req)
+	return c, err
+}
+
+func (c *wsTestClient) writeFrame(b0 byte, payload []byte, masked bool) {
+	h := []byte{b0, 0}
+	switch n := len(payload); {
+	case n <= 125:
+		h[1] = byte(n)
+	default:
+		h[1] = 126
+		h = append(h, byte(n>>8), byte(n))
+	}
+
+	if !masked {
+		c.conn.Write(append(h, payload...))
+		return
+	}
+
+	h[1] |= 0x80
+	mask := []byte{1, 2, 3, 4}
+	h = append(h, mask...)
+	data := make([]byte, len(payload))
+	for i := range payload {
+		data[i] = payload[i] ^ mask[i%4]
+	}
+	c.conn.Write(append(h, data...))
+}
+
+func (c *wsTestClient) readFrame() (byte, []byte, error) {
+	c.conn.SetReadDeadline(time.Now().Add(time.Second))
+
+	h := make([]byte, 2)
+	if _, err := io.ReadFull(c.br, h); err != nil {
+		return 0, nil, err
+	}
+
+	n := int(h[1] & 0x7f)
+	if n == 126 {
+		io.ReadFull(c.br, h)
+		n = int(binary.BigEndian.Uint16(h))
+	}
+
+	payload := make([]byte, n)
+	_, err := io.ReadFull(c.br, payload)
+	return h[0], payload, err
+}
+
+func TestWebSocket(t *testing.T) {
+	r := NewRouter()
+	r.GET("/ws", func(ctx *Context) {
+		conn, err := ctx.UpgradeWebSocket(&WebSocketOptions{
+			Subprotocols: []string{"v2", "chat"},
+			ReadLimit:    200,
+		})
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			typ, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			conn.WriteMessage(typ, data)
+		}
+	})
+	srv := httptest.NewServer(r.Handler())
+	defer srv.Close()
+	addr := strings.TrimPrefix(srv.URL, "http://")
+
+	Convey("handshake", t, func() {
+		c, err := dialWebSocket(addr, "/ws", http.Header{HeaderSecWebSocketProtocol: {"chat, v2"}})
+		So(err, ShouldBeNil)
+		defer c.conn.Close()
+
+		So(c.resp.StatusCode, ShouldEqual, http.StatusSwitchingProtocols)
+		So(c.resp.Header.Get(HeaderSecWebSocketAccept), ShouldEqual, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=")
+		So(c.resp.Header.Get(HeaderSecWebSocketProtocol), ShouldEqual, "v2")
+
+		c, err = dialWebSocket(addr, "/ws", http.Header{HeaderOrigin: {"http://evil.com"}})
+		So(err, ShouldBeNil)
+		So(c.resp.StatusCode, ShouldEqual, http.StatusForbidden)
+
+		c, err = dialWebSocket(addr, "/ws", http.Header{HeaderSecWebSocketVersion: {"8"}})
+		So(err, ShouldBeNil)
+		So(c.resp.StatusCode, ShouldEqual, http.StatusUpgradeRequired)
+		So(c.resp.Header.Get(HeaderSecWebSocketVersion), ShouldEqual, "13")
+	})
+
+	Convey("messages, fragments and control frames", t, func() {
+		c, err := dialWebSocket(addr, "/ws", nil)
+		So(err, ShouldBeNil)
+		defer c.conn.Close()
+
+		c.writeFrame(0x81, []byte("hello"), true)
+		b0, data, err := c.readFrame()
+		So(err, ShouldBeNil)
+		So(b0, ShouldEqual, 0x81)
+		So(string(data), ShouldEqual, "hello")
+
+		// fragmented text with a ping between the fragments
+		c.writeFrame(0x01, []byte("wa"), true)
+		c.writeFrame(0x89, []byte("p"), true)
+		c.writeFrame(0x80, []byte("ter"), true)
+
+		b0, data, _ = c.readFrame()
+		So(b0, ShouldEqual, 0x8A) // pong
+		So(string(data), ShouldEqual, "p")
+		b0, data, _ = c.readFrame()
+		So(b0, ShouldEqual, 0x81)
+		So(string(data), ShouldEqual, "water")
+
+		c.writeFrame(0x82, []byte{0, 1, 2}, true)
+		b0, data, _ = c.readFrame()
+		So(b0, ShouldEqual, 0x82)
+		So(data, ShouldResemble, []byte{0, 1, 2})
+
+		c.writeFrame(0x88, []byte{0x03, 0xe8}, true) // 1000
+		b0, data, _ = c.readFrame()
+		So(b0, ShouldEqual, 0x88)
+		So(binary.BigEndian.Uint16(data), ShouldEqual, WebSocketCloseNormal)
+	})
+
+	Convey("protocol errors", t, func() {
+		cases := []struct {
+			b0      byte
+			payload []byte
+			masked  bool
+			code    int
+		}{
+			{0x81, []byte("a"), false, WebSocketCloseProtocolError},              // unmasked
+			{0xC1, []byte("a"), true, WebSocketCloseProtocolError},               // rsv1
+			{0x83, []byte("a"), true, WebSocketCloseProtocolError},               // reserved opcode
+			{0x80, []byte("a"), true, WebSocketCloseProtocolError},               // bad continuation
+			{0x81, []byte{0xff, 0xfe}, true, WebSocketCloseInvalidPayload},       // invalid utf-8
+			{0x82, []byte(strings.Repeat("a", 201)), true, WebSocketCloseTooBig}, // read limit
+			{0x88, []byte{0x03, 0xed}, true, WebSocketCloseProtocolError},        // close 1005
+			{0x09, []byte("fragmented ping"), true, WebSocketCloseProtocolError}, // control without FIN
+		}
+
+		for _, v := range cases {
+			c, err := dialWebSocket(addr, "/ws", nil)
+			So(err, ShouldBeNil)
+
+			c.writeFrame(v.b0, v.payload, v.masked)
+			b0, data, err := c.readFrame()
+			So(err, ShouldBeNil)
+			So(b0, ShouldEqual, 0x88)
+			So(binary.BigEndian.Uint16(data), ShouldEqual, v.code)
+
+			c.conn.Close()
+		}
+	})
+}
+
+func TestWebSocketMaxFrameSize(t *testing.T) {
+	r := NewRouter()
+	r.GET("/ws", func(ctx *Context) {
+		conn, err := ctx.UpgradeWebSocket(&WebSocketOptions{MaxFrameSize: 4})
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		conn.WriteText("abcdefghij")
+	})
+	srv := httptest.NewServer(r.Handler())
+	defer srv.Close()
+
+	Convey("split the sent message", t, func() {
+		c, err := dialWebSocket(strings.TrimPrefix(srv.URL, "http://"), "/ws", nil)
+		So(err, ShouldBeNil)
+		defer c.conn.Close()
+
+		for _, want := range []struct {
+			b0   byte
+			data string
+		}{{0x01, "abcd"}, {0x00, "efgh"}, {0x80, "ij"}} {
+			b0, data, err := c.readFrame()
+			So(err, ShouldBeNil)
+			So(b0, ShouldEqual, want.b0)
+			So(string(data), ShouldEqual, want.data)
+		}
+	})
+}